@@ -3,6 +3,7 @@ package auth
 
 import (
 	"net/http"
+	"slices"
 
 	"github.com/ProjectLighthouseCAU/beacon/types"
 )
@@ -12,6 +13,12 @@ type Auth interface {
 	IsAuthorized(*types.Client, *types.Request) (bool, int)
 }
 
+// UserAuth is implemented by auth implementations that can authorize the requests of a user
+// that was already authenticated without a token (e.g. with a TLS client certificate, see certificateAuth)
+type UserAuth interface {
+	IsUserAuthorized(client *types.Client, username string, req *types.Request) (bool, int)
+}
+
 // Helper function for determining if an operation is read-only
 func IsReadOperation(req *types.Request) bool {
 	return map[string]bool{
@@ -19,13 +26,33 @@ func IsReadOperation(req *types.Request) bool {
 		"GET":    true,
 		"STREAM": true,
 		"STOP":   true,
+		"WATCH":  true,
 	}[req.VERB]
 }
 
-// Helper function for determining if an operation is read-write (not create, mkdir or delete)
+// Helper function for determining if an operation is read-write (not create, mkdir or delete), i.e. PUT and PATCH
 // POST, CREATE, MKDIR, DELETE, (LINK, UNLINK) should only be used by admin (or deploy)
 func IsReadWriteOperation(req *types.Request) bool {
-	return IsReadOperation(req) || req.VERB == "PUT"
+	return IsReadOperation(req) || req.VERB == "PUT" || req.VERB == "PATCH"
+}
+
+// SourceRequest returns a request on the source path (payload) of a request that uses another resource or directory
+// for checking the permissions on the source: reading for LINK and COPY (like GET), removing for MOVE (like DELETE).
+// Returns false if the request has no source (or the payload is not a path).
+func SourceRequest(req *types.Request) (*types.Request, bool) {
+	verb, ok := map[string]string{
+		"LINK": "GET",
+		"COPY": "GET",
+		"MOVE": "DELETE",
+	}[req.VERB]
+	if !ok {
+		return nil, false
+	}
+	sourcePath, err := req.PayloadToPath()
+	if err != nil {
+		return nil, false
+	}
+	return &types.Request{REID: req.REID, AUTH: req.AUTH, VERB: verb, PATH: sourcePath, META: req.META}, true
 }
 
 // --- Combined Authorization Handlers ---
@@ -105,3 +132,90 @@ func AllowNone() *allowNone {
 func (a *allowNone) IsAuthorized(c *types.Client, req *types.Request) (bool, int) {
 	return false, http.StatusUnauthorized
 }
+
+// --- Restricting Authorization Handlers ---
+
+// readOnlyAuth only allows read operations that are also allowed by the wrapped auth
+type readOnlyAuth struct {
+	auth Auth
+}
+
+var _ Auth = (*readOnlyAuth)(nil)
+
+func NewReadOnlyAuth(auth Auth) *readOnlyAuth {
+	return &readOnlyAuth{auth}
+}
+
+// IsAuthorized determines whether a request is authorized
+func (a *readOnlyAuth) IsAuthorized(c *types.Client, req *types.Request) (bool, int) {
+	if !IsReadOperation(req) {
+		return false, http.StatusForbidden
+	}
+	return a.auth.IsAuthorized(c, req)
+}
+
+// subtreeAuth only allows requests on paths in a subtree of the directory that are also allowed by the wrapped auth
+type subtreeAuth struct {
+	auth    Auth
+	subtree []string
+}
+
+var _ Auth = (*subtreeAuth)(nil)
+
+func NewSubtreeAuth(auth Auth, subtree []string) *subtreeAuth {
+	return &subtreeAuth{auth, subtree}
+}
+
+// IsAuthorized determines whether a request is authorized
+func (a *subtreeAuth) IsAuthorized(c *types.Client, req *types.Request) (bool, int) {
+	if !a.contains(req.PATH) {
+		return false, http.StatusForbidden
+	}
+	if req.VERB == "LINK" || req.VERB == "UNLINK" { // the source path must also be in the subtree
+		sourcePath, err := req.PayloadToPath()
+		if err != nil || !a.contains(sourcePath) {
+			return false, http.StatusForbidden
+		}
+	}
+	return a.auth.IsAuthorized(c, req)
+}
+
+func (a *subtreeAuth) contains(path []string) bool {
+	return len(path) >= len(a.subtree) && slices.Equal(path[:len(a.subtree)], a.subtree)
+}
+
+// --- Client Certificate Authorization ---
+
+// certificateAuth authorizes the requests of clients that authenticated with a TLS client certificate
+// with the permissions of the user that the certificate is mapped to (without a token if the wrapped auth implements UserAuth)
+// and passes all other requests on to the wrapped auth
+type certificateAuth struct {
+	auth Auth
+}
+
+var _ Auth = (*certificateAuth)(nil)
+
+func NewCertificateAuth(auth Auth) *certificateAuth {
+	return &certificateAuth{auth}
+}
+
+// IsAuthorized determines whether a request is authorized
+func (a *certificateAuth) IsAuthorized(c *types.Client, req *types.Request) (bool, int) {
+	username := c.CertificateUser()
+	if username == "" {
+		return a.auth.IsAuthorized(c, req)
+	}
+	if user, ok := req.AUTH["USER"]; ok && user != username { // acting as another user requires a token
+		return a.auth.IsAuthorized(c, req)
+	}
+	if req.AUTH == nil {
+		req.AUTH = map[string]string{}
+	}
+	req.AUTH["USER"] = username // the request is authorized with the permissions of the user (and e.g. per user rate limits)
+	if _, ok := req.AUTH["TOKEN"]; !ok {
+		if userAuth, ok := a.auth.(UserAuth); ok {
+			return userAuth.IsUserAuthorized(c, username, req)
+		}
+	}
+	return a.auth.IsAuthorized(c, req) // the user authenticates with a token as well
+}
@@ -0,0 +1,54 @@
+package auth_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ProjectLighthouseCAU/beacon/auth"
+	"github.com/ProjectLighthouseCAU/beacon/auth/hardcoded"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+func TestCertificateAuth(t *testing.T) {
+	a := auth.NewCertificateAuth(&hardcoded.AllowCustom{
+		Users:  map[string]string{"exporter": "exporter-token", "admin": "admin-token"},
+		Admins: map[string]bool{"admin": true},
+	})
+	tests := []struct {
+		certUser string
+		auth     map[string]string
+		verb     string
+		path     []string
+		expected bool
+		code     int
+	}{
+		{"exporter", nil, "PUT", []string{"user", "exporter", "model"}, true, http.StatusOK},
+		{"exporter", nil, "GET", []string{"user", "other", "model"}, true, http.StatusOK},
+		{"exporter", nil, "PUT", []string{"user", "other", "model"}, false, http.StatusForbidden},
+		{"exporter", nil, "DELETE", []string{"user", "exporter", "model"}, false, http.StatusForbidden},
+		{"exporter", map[string]string{"USER": "exporter"}, "PUT", []string{"user", "exporter", "model"}, true, http.StatusOK},
+		{"admin", nil, "DELETE", []string{"user", "exporter", "model"}, true, http.StatusOK},
+		{"unknown", nil, "GET", []string{"user", "exporter", "model"}, false, http.StatusUnauthorized},
+		// acting as another user requires the token of that user
+		{"exporter", map[string]string{"USER": "admin"}, "DELETE", []string{"user", "exporter", "model"}, false, http.StatusUnauthorized},
+		{"exporter", map[string]string{"USER": "admin", "TOKEN": "admin-token"}, "DELETE", []string{"user", "exporter", "model"}, true, http.StatusOK},
+		// a token of the certificate user is checked as well
+		{"exporter", map[string]string{"TOKEN": "wrong"}, "GET", []string{"user", "exporter", "model"}, false, http.StatusUnauthorized},
+		// clients without certificate authenticate with their token
+		{"", nil, "GET", []string{"user", "exporter", "model"}, false, http.StatusUnauthorized},
+		{"", map[string]string{"USER": "exporter", "TOKEN": "exporter-token"}, "PUT", []string{"user", "exporter", "model"}, true, http.StatusOK},
+	}
+	for _, test := range tests {
+		client := types.NewClient("client", nil)
+		client.SetCertificateUser(test.certUser)
+		request := &types.Request{AUTH: test.auth, VERB: test.verb, PATH: test.path}
+		ok, code := a.IsAuthorized(client, request)
+		if ok != test.expected || code != test.code {
+			t.Fatalf("%s %v of certificate user %q with AUTH %v: expected %t (%d), got %t (%d)",
+				test.verb, test.path, test.certUser, test.auth, test.expected, test.code, ok, code)
+		}
+		if test.certUser != "" && test.auth["USER"] == "" && request.AUTH["USER"] != test.certUser {
+			t.Fatalf("request of certificate user %q has AUTH USER %q", test.certUser, request.AUTH["USER"])
+		}
+	}
+}
@@ -67,7 +67,23 @@ func (a *AllowCustom) IsAuthorized(c *types.Client, req *types.Request) (bool, i
 	if token != correctToken {
 		return false, http.StatusUnauthorized
 	}
+	return a.permissions(username, req)
+}
+
+var _ auth.UserAuth = (*AllowCustom)(nil)
+
+// IsUserAuthorized determines whether a request of a user that was authenticated without a token is authorized
+func (a *AllowCustom) IsUserAuthorized(c *types.Client, username string, req *types.Request) (bool, int) {
+	a.Lock.RLock()
+	defer a.Lock.RUnlock()
+	if _, ok := a.Users[username]; !ok {
+		return false, http.StatusUnauthorized
+	}
+	return a.permissions(username, req)
+}
 
+// permissions determines whether an authenticated user is allowed to perform a request (the lock must be held)
+func (a *AllowCustom) permissions(username string, req *types.Request) (bool, int) {
 	isAdmin := a.Admins[username]
 	if isAdmin {
 		return true, http.StatusOK
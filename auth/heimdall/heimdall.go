@@ -289,14 +289,26 @@ func (a *HeimdallAuth) IsAuthorized(client *types.Client, request *types.Request
 	if config.VerboseLogging {
 		log.Printf("[HeimdallAuth] Authenticated user %s with entry: %+v\n", username, entry)
 	}
+	return permissions(username, entry.Roles, request)
+}
+
+var _ auth.UserAuth = (*HeimdallAuth)(nil)
+
+// IsUserAuthorized determines whether a request of a user that was authenticated without a token is authorized.
+// The roles of a user are only known with a token, so only the permissions of users without roles apply.
+func (a *HeimdallAuth) IsUserAuthorized(client *types.Client, username string, request *types.Request) (bool, int) {
+	return permissions(username, nil, request)
+}
 
+// permissions determines whether an authenticated user with the given roles is allowed to perform a request
+func permissions(username string, roles []string, request *types.Request) (bool, int) {
 	// admin role can perform any action on any path
-	if slices.Contains(entry.Roles, config.HeimdallAdminRolename) {
+	if slices.Contains(roles, config.HeimdallAdminRolename) {
 		return true, http.StatusOK
 	}
 
 	// deploy role can read and write to /metrics
-	if slices.Contains(entry.Roles, config.HeimdallDeployRolename) {
+	if slices.Contains(roles, config.HeimdallDeployRolename) {
 		if len(request.PATH) > 0 && request.PATH[0] == "metrics" {
 			return true, http.StatusOK
 		}
@@ -319,8 +331,8 @@ func (a *HeimdallAuth) IsAuthorized(client *types.Client, request *types.Request
 		return true, http.StatusOK
 	}
 
-	// allow users to list directories
-	if request.VERB == "LIST" {
+	// allow users to list and watch directories
+	if request.VERB == "LIST" || request.VERB == "WATCH" {
 		return true, http.StatusOK
 	}
 
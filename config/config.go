@@ -4,16 +4,86 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// Version of the server, set at build time with -ldflags "-X github.com/ProjectLighthouseCAU/beacon/config.Version=..."
+var Version = "dev"
+
 var (
+	// endpoints
+	Endpoints []string = GetStringList("ENDPOINTS", []string{"websocket"}) // valid values: websocket, tcp, unix, udp, rest
+
+	// connection timeouts (0 disables the timeout)
+	ConnectionIdleTimeout         time.Duration = GetDuration("CONNECTION_IDLE_TIMEOUT", 10*time.Minute)         // disconnect clients without requests and open streams
+	ConnectionUnauthorizedTimeout time.Duration = GetDuration("CONNECTION_UNAUTHORIZED_TIMEOUT", 30*time.Second) // disconnect clients that did not send any authorized request
+
+	// connection limits of all endpoints (0 means unlimited)
+	MaxConnections      int = GetInt("MAX_CONNECTIONS", 0)
+	MaxConnectionsPerIp int = GetInt("MAX_CONNECTIONS_PER_IP", 0)
+	// reverse proxies (IP addresses or CIDRs) whose X-Real-Ip and X-Forwarded-For headers are trusted
+	// (only loopback by default, proxies in other networks, e.g. a docker network, must be added explicitly)
+	TrustedProxies []string = GetStringList("TRUSTED_PROXIES", []string{"127.0.0.0/8", "::1"})
+
+	// TLS of the HTTP endpoints (websocket and rest), disabled if no certificate and key are configured
+	TlsCertFile            string        = GetString("TLS_CERT_FILE", "")
+	TlsKeyFile             string        = GetString("TLS_KEY_FILE", "")
+	TlsReloadInterval      time.Duration = GetDuration("TLS_RELOAD_INTERVAL", 10*time.Second) // interval for checking the certificate files for changes
+	TlsClientCaFile        string        = GetString("TLS_CLIENT_CA_FILE", "")                // enables (optional) client certificate authentication
+	TlsClientCertUsersJson string        = GetString("TLS_CLIENT_CERT_USERS_JSON", "{}")      // certificate subject (or common name) -> username
+
+	// time a disconnected client can resume its session (and streams) after reconnecting (0 disables session resumption)
+	SessionGracePeriod time.Duration = GetDuration("SESSION_GRACE_PERIOD", 30*time.Second)
+
+	// received requests per connection that wait to be processed (reading from the connection blocks while the queue is full)
+	RequestQueueSize int = GetInt("REQUEST_QUEUE_SIZE", 16)
+
+	// maximum number of requests in a BATCH request
+	BatchMaxSize int = GetInt("BATCH_MAX_SIZE", 256)
+
+	// send queue of every connection
+	SendQueueSize   int    = GetInt("SEND_QUEUE_SIZE", 64)                 // responses per connection that wait to be written
+	SendQueuePolicy string = GetString("SEND_QUEUE_POLICY", "drop_oldest") // valid values: drop_oldest, drop_newest, disconnect (what happens if the send queue is full)
+
 	// websocket
-	WebsocketHost            string = GetString("WEBSOCKET_HOST", "127.0.0.1")
-	WebsocketPort            int    = GetInt("WEBSOCKET_PORT", 3000)
-	WebsocketReadBufferSize  int    = GetInt("WEBSOCKET_READ_BUFFER_SIZE", 0)
-	WebsocketWriteBufferSize int    = GetInt("WEBSOCKET_WRITE_BUFFER_SIZE", 0)
-	WebsocketReadLimit       int    = GetInt("WEBSOCKET_READ_LIMIT", 2048)
+	WebsocketHost               string        = GetString("WEBSOCKET_HOST", "127.0.0.1")
+	WebsocketPort               int           = GetInt("WEBSOCKET_PORT", 3000)
+	WebsocketRoute              string        = GetString("WEBSOCKET_ROUTE", "/")
+	WebsocketRoutesJson         string        = GetString("WEBSOCKET_ROUTES_JSON", "") // multiple routes with their own auth (see websocket.RouteConfig), replaces WEBSOCKET_ROUTE
+	WebsocketReadBufferSize     int           = GetInt("WEBSOCKET_READ_BUFFER_SIZE", 0)
+	WebsocketWriteBufferSize    int           = GetInt("WEBSOCKET_WRITE_BUFFER_SIZE", 0)
+	WebsocketReadLimit          int           = GetInt("WEBSOCKET_READ_LIMIT", 2048)
+	WebsocketCompression        bool          = GetBool("WEBSOCKET_COMPRESSION", false)       // permessage-deflate for clients that support it
+	WebsocketCompressionLevel   int           = GetInt("WEBSOCKET_COMPRESSION_LEVEL", 1)      // 1 (best speed) to 9 (best compression)
+	WebsocketCompressionMinSize int           = GetInt("WEBSOCKET_COMPRESSION_MIN_SIZE", 256) // smaller messages are not compressed
+	WebsocketPingInterval       time.Duration = GetDuration("WEBSOCKET_PING_INTERVAL", 30*time.Second)
+	WebsocketPongTimeout        time.Duration = GetDuration("WEBSOCKET_PONG_TIMEOUT", 60*time.Second) // must be greater than the ping interval
+	WebsocketCloseTimeout       time.Duration = GetDuration("WEBSOCKET_CLOSE_TIMEOUT", 3*time.Second) // time to wait for the client to answer a close message
+
+	// tcp
+	TcpHost      string = GetString("TCP_HOST", "127.0.0.1")
+	TcpPort      int    = GetInt("TCP_PORT", 3002)
+	TcpReadLimit int    = GetInt("TCP_READ_LIMIT", 2048)
+
+	// unix domain socket
+	UnixSocketPath      string      = GetString("UNIX_SOCKET_PATH", "./beacon.sock")
+	UnixSocketMode      os.FileMode = GetFileMode("UNIX_SOCKET_MODE", 0660)
+	UnixSocketReadLimit int         = GetInt("UNIX_SOCKET_READ_LIMIT", 2048)
+
+	// udp
+	UdpHost          string        = GetString("UDP_HOST", "127.0.0.1")
+	UdpPort          int           = GetInt("UDP_PORT", 3003)
+	UdpReadLimit     int           = GetInt("UDP_READ_LIMIT", 4096) // maximum datagram size, larger datagrams are truncated
+	UdpPeerTimeout   time.Duration = GetDuration("UDP_PEER_TIMEOUT", 30*time.Second)
+	UdpPeerQueueSize int           = GetInt("UDP_PEER_QUEUE_SIZE", 10) // received datagrams per peer that wait for processing
+	UdpMaxPeers      int           = GetInt("UDP_MAX_PEERS", 1024)     // peers are only registered if their first datagram is an authorized request
+
+	// rest
+	RestHost                    string        = GetString("REST_HOST", "127.0.0.1")
+	RestPort                    int           = GetInt("REST_PORT", 3004)
+	RestReadLimit               int           = GetInt("REST_READ_LIMIT", 8192) // maximum request body size (JSON is larger than MessagePack)
+	RestStreamKeepAliveInterval time.Duration = GetDuration("REST_STREAM_KEEP_ALIVE_INTERVAL", 15*time.Second)
 
 	// snapshot
 	SnapshotPath     string        = GetString("SNAPSHOT_PATH", "./snapshot.beacon")
@@ -33,6 +103,9 @@ var (
 	BeaconToken             string = GetString("BEACON_TOKEN", "")
 	ContainerName           string = GetString("CONTAINER_NAME", "beacon")
 
+	// rate limits per connection, user and role (see ratelimit.Config), e.g. {"user": {"PUT": {"rate": 60, "burst": 120}}}
+	RateLimitsJson string = GetString("RATE_LIMITS_JSON", "{}")
+
 	// legacy
 	LegacyDatabaseHost     string        = GetString("DB_HOST", "localhost")
 	LegacyDatabasePort     int           = GetInt("DB_PORT", 5432)
@@ -48,6 +121,8 @@ var (
 	ResourceImplementation string = GetString("RESOURCE_IMPL", "brokerless") // valid values: broker, brokerless
 	// stream
 	ResourceStreamChannelSize int = GetInt("RESOURCE_STREAM_CHANNEL_SIZE", 10)
+	// watch (events per WATCH request that wait to be sent)
+	WatchChannelSize int = GetInt("WATCH_CHANNEL_SIZE", 100)
 	// broker-specific
 	ResourceInputChannelSize   int = GetInt("RESOURCE_PUT_CHANNEL_SIZE", 10)
 	ResourceControlChannelSize int = GetInt("RESOURCE_CONTROL_CHANNEL_SIZE", 10)
@@ -65,6 +140,21 @@ func GetString(key string, defaultValue string) string {
 	return defaultValue
 }
 
+// GetStringList reads a comma separated list (whitespace around the elements is ignored)
+func GetStringList(key string, defaultValue []string) []string {
+	if value, exists := os.LookupEnv(key); exists {
+		list := []string{}
+		for _, elem := range strings.Split(value, ",") {
+			elem = strings.TrimSpace(elem)
+			if elem != "" {
+				list = append(list, elem)
+			}
+		}
+		return list
+	}
+	return defaultValue
+}
+
 func GetInt(key string, defaultValue int) int {
 	if value, exists := os.LookupEnv(key); exists {
 		i, err := strconv.Atoi(value)
@@ -100,3 +190,15 @@ func GetDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+func GetFileMode(key string, defaultValue os.FileMode) os.FileMode {
+	if value, exists := os.LookupEnv(key); exists {
+		m, err := strconv.ParseUint(value, 8, 32)
+		if err != nil {
+			log.Printf("Found Config %s=%s, but could not parse it (octal file mode required, e.g. \"0660\")", key, value)
+			return defaultValue
+		}
+		return os.FileMode(m)
+	}
+	return defaultValue
+}
@@ -1,5 +1,43 @@
 package directory
 
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// returned (wrapped) if a leaf or directory cannot be created because the path already exists
+var ErrAlreadyExists = errors.New("already exists")
+
+// Event describes a change of the directory (see Directory.AddHook)
+type Event struct {
+	Kind   EventKind
+	Path   []string
+	Source []string // previous path (Moved), copied path (Created by Copy) or linked leaf (Linked, Unlinked), nil otherwise
+	Leaf   bool     // whether the path is a leaf or a directory
+}
+
+// Affects returns whether the event concerns the path, its subtree or one of its ancestors (e.g. deleting or moving a parent directory)
+func (e Event) Affects(path []string) bool {
+	return related(e.Path, path) || (e.Source != nil && related(e.Source, path))
+}
+
+// related returns whether one of the paths is in the subtree of the other one
+func related(a, b []string) bool {
+	n := min(len(a), len(b))
+	return slices.Equal(a[:n], b[:n])
+}
+
+type EventKind string
+
+const (
+	Created  EventKind = "CREATED"
+	Deleted  EventKind = "DELETED"
+	Moved    EventKind = "MOVED"
+	Linked   EventKind = "LINKED"
+	Unlinked EventKind = "UNLINKED"
+)
+
 // Directory defines the directory tree for bookkeeping of the resources.
 type Directory[T any] interface {
 	// Creates a leaf at a given path and creates the parent directories if they don't exist.
@@ -14,6 +52,15 @@ type Directory[T any] interface {
 	// Returns an error if the leaf or directory does not exist.
 	Delete(path []string) error
 
+	// Moves a leaf or directory (keeping the same values) to a new path and creates the parent directories if they don't exist.
+	// Returns an error if the source does not exist, the destination already exists or is inside the source.
+	Move(source, destination []string) error
+
+	// Copies a leaf or directory (recursively) to a new path and creates the parent directories if they don't exist.
+	// The value of every leaf is duplicated with the copy function that receives the destination path of the leaf.
+	// Returns an error if the source does not exist, the destination already exists or is inside the source.
+	Copy(source, destination []string, copy func(path []string, value T) T) error
+
 	// Returns a leaf at a given path.
 	// Returns an error if the leaf does not exist
 	GetLeaf(path []string) (T, error)
@@ -24,12 +71,23 @@ type Directory[T any] interface {
 	// Executes a function on every leaf in the directory.
 	// When the provided function returns false, further execution is stopped.
 	// When the provided function returns an error, the error is returned and further execution is also stopped.
-	ForEach(path []string, f func(path []string, value T) (bool, error)) error
+	// When the context ends, the error of the context is returned and further execution is also stopped.
+	ForEach(ctx context.Context, path []string, f func(path []string, value T) (bool, error)) error
 
 	// Returns the directories entries as a list
 	List(path []string) (map[string]any, error)
 	// Returns the directories subtree structure as a nested map
-	ListRecursive(path []string) (map[string]any, error)
+	// (or the error of the context if it ends before the subtree is complete)
+	ListRecursive(ctx context.Context, path []string) (map[string]any, error)
+
+	// Registers a hook that is called with every change of the directory (in the order of the changes, changing the root with ChRoot is not reported).
+	// Hooks are called while the directory is locked, so they must neither block nor use the directory.
+	// Events passed to Notify are ordered by the time Notify is called, not by the time of the change they describe.
+	// Returns a function that removes the hook (no hook call is running anymore when it returns).
+	AddHook(hook func(Event)) (remove func())
+	// Calls the hooks with an event that is not caused by the directory itself (e.g. linking the values of two leafs)
+	// while the directory is locked (so the event is not interleaved with the changes of the directory)
+	Notify(event Event)
 
 	// Changes the root directory of this directory to the given directories root
 	ChRoot(dir Directory[T]) error
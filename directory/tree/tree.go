@@ -1,8 +1,10 @@
 package tree
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 
@@ -17,6 +19,10 @@ var _ directoryPkg.Directory[any] = (*directory[any])(nil) // directory type imp
 type directory[T any] struct {
 	root tree
 	lock sync.RWMutex
+
+	hooks     map[uint64]func(directoryPkg.Event)
+	nextHook  uint64
+	hooksLock sync.RWMutex
 }
 
 func NewTree[T any]() directoryPkg.Directory[T] {
@@ -24,6 +30,7 @@ func NewTree[T any]() directoryPkg.Directory[T] {
 		root: &node[T]{
 			entries: make(map[string]tree),
 		},
+		hooks: make(map[uint64]func(directoryPkg.Event)),
 	}
 }
 
@@ -94,11 +101,12 @@ func (d *directory[T]) CreateLeaf(path []string, value T) error {
 	}
 	_, ok := n.entries[path[len(path)-1]]
 	if ok {
-		return errors.New(path[len(path)-1] + " in " + strings.Join(path, "/") + " already exists")
+		return fmt.Errorf("%s in %s %w", path[len(path)-1], strings.Join(path, "/"), directoryPkg.ErrAlreadyExists)
 	}
 	n.entries[path[len(path)-1]] = &leaf[T]{
 		value,
 	}
+	d.notify(directoryPkg.Event{Kind: directoryPkg.Created, Path: path, Leaf: true})
 	return nil
 }
 
@@ -111,12 +119,13 @@ func (d *directory[T]) CreateDirectory(path []string) error {
 	}
 	n, _ := d.getDirectory(path, false)
 	if n != nil {
-		return errors.New("directory " + strings.Join(path, "/") + " already exists")
+		return fmt.Errorf("directory %s %w", strings.Join(path, "/"), directoryPkg.ErrAlreadyExists)
 	}
 	_, err := d.getDirectory(path, true) // create missing directories in path
 	if err != nil {
 		return err
 	}
+	d.notify(directoryPkg.Event{Kind: directoryPkg.Created, Path: path})
 	return nil
 }
 
@@ -131,11 +140,98 @@ func (d *directory[T]) Delete(path []string) error {
 	if err != nil {
 		return err
 	}
-	_, ok := n.entries[path[len(path)-1]]
+	entry, ok := n.entries[path[len(path)-1]]
 	if !ok {
 		return errors.New(path[len(path)-1] + " not found in " + strings.Join(path, "/"))
 	}
 	delete(n.entries, path[len(path)-1])
+	d.notify(directoryPkg.Event{Kind: directoryPkg.Deleted, Path: path, Leaf: isLeaf[T](entry)})
+	return nil
+}
+
+// Move moves a resource or a directory to a new path while creating missing directories
+func (d *directory[T]) Move(source, destination []string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if len(source) == 0 {
+		return errors.New("cannot move root directory")
+	}
+	src, entry, err := d.getEntry(source)
+	if err != nil {
+		return err
+	}
+	dst, err := d.getDestination(source, destination)
+	if err != nil {
+		return err
+	}
+	delete(src.entries, source[len(source)-1])
+	dst.entries[destination[len(destination)-1]] = entry
+	d.notify(directoryPkg.Event{Kind: directoryPkg.Moved, Path: destination, Source: source, Leaf: isLeaf[T](entry)})
+	return nil
+}
+
+// Copy copies a resource or a directory (recursively) to a new path while creating missing directories
+func (d *directory[T]) Copy(source, destination []string, copy func(path []string, value T) T) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if len(source) == 0 {
+		return errors.New("cannot copy root directory")
+	}
+	_, entry, err := d.getEntry(source)
+	if err != nil {
+		return err
+	}
+	dst, err := d.getDestination(source, destination)
+	if err != nil {
+		return err
+	}
+	dst.entries[destination[len(destination)-1]] = copyTree(entry, destination, copy)
+	d.notify(directoryPkg.Event{Kind: directoryPkg.Created, Path: destination, Source: source, Leaf: isLeaf[T](entry)})
+	return nil
+}
+
+// getEntry returns the leaf or directory at a path (except root) and its parent directory
+func (d *directory[T]) getEntry(path []string) (*node[T], tree, error) {
+	n, err := d.getDirectory(path[0:len(path)-1], false)
+	if err != nil {
+		return nil, nil, err
+	}
+	entry, ok := n.entries[path[len(path)-1]]
+	if !ok {
+		return nil, nil, errors.New(path[len(path)-1] + " not found in " + strings.Join(path, "/"))
+	}
+	return n, entry, nil
+}
+
+// getDestination returns the parent directory of the destination of a move or copy (while creating missing directories)
+func (d *directory[T]) getDestination(source, destination []string) (*node[T], error) {
+	if len(destination) == 0 {
+		return nil, fmt.Errorf("root directory %w", directoryPkg.ErrAlreadyExists)
+	}
+	if len(destination) >= len(source) && slices.Equal(destination[:len(source)], source) {
+		return nil, errors.New("cannot move or copy " + strings.Join(source, "/") + " into itself")
+	}
+	n, err := d.getDirectory(destination[0:len(destination)-1], true)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := n.entries[destination[len(destination)-1]]; ok {
+		return nil, fmt.Errorf("%s in %s %w", destination[len(destination)-1], strings.Join(destination, "/"), directoryPkg.ErrAlreadyExists)
+	}
+	return n, nil
+}
+
+func copyTree[T any](t tree, path []string, copy func(path []string, value T) T) tree {
+	switch x := t.(type) {
+	case *leaf[T]:
+		return &leaf[T]{copy(path, x.value)}
+	case *node[T]:
+		n := &node[T]{entries: make(map[string]tree, len(x.entries))}
+		for name, entry := range x.entries {
+			n.entries[name] = copyTree(entry, util.ImmutableAppend(path, name), copy)
+		}
+		return n
+	}
 	return nil
 }
 
@@ -218,7 +314,8 @@ func (n *node[T]) string(prefixAtLayer []bool) string {
 // ForEach executes a function on every resource in the directory.
 // When the provided function returns false, further execution is stopped.
 // When the provided function returns an error, the error is returned and further execution is also stopped.
-func (d *directory[T]) ForEach(path []string, f func(path []string, value T) (bool, error)) error {
+// When the context ends, the error of the context is returned and further execution is also stopped.
+func (d *directory[T]) ForEach(ctx context.Context, path []string, f func(path []string, value T) (bool, error)) error {
 	l, err := d.GetLeaf(path)
 	if err == nil {
 		f(path, l)
@@ -230,14 +327,17 @@ func (d *directory[T]) ForEach(path []string, f func(path []string, value T) (bo
 	if err != nil {
 		return err
 	}
-	return forEach(n, path, f)
+	return forEach(ctx, n, path, f)
 }
 
-func forEach[T any](t tree, path []string, f func(path []string, value T) (bool, error)) (err error) {
+func forEach[T any](ctx context.Context, t tree, path []string, f func(path []string, value T) (bool, error)) (err error) {
 	switch x := t.(type) {
 	case *node[T]:
 		for entryName, subt := range x.entries {
-			err = forEach(subt, util.ImmutableAppend(path, entryName), f)
+			if err = ctx.Err(); err != nil {
+				return
+			}
+			err = forEach(ctx, subt, util.ImmutableAppend(path, entryName), f)
 			if err != nil {
 				return
 			}
@@ -273,7 +373,7 @@ func (d *directory[T]) List(path []string) (map[string]any, error) {
 
 // List lists the contents of a directory by returning a recursively nested map of subdirectories.
 // A resource is indicated by a nil value.
-func (d *directory[T]) ListRecursive(path []string) (map[string]any, error) {
+func (d *directory[T]) ListRecursive(ctx context.Context, path []string) (map[string]any, error) {
 	d.lock.RLock()
 	defer d.lock.RUnlock()
 
@@ -281,14 +381,17 @@ func (d *directory[T]) ListRecursive(path []string) (map[string]any, error) {
 	if err != nil {
 		return nil, err
 	}
-	m, err := list(n)
+	m, err := list(ctx, n)
 	if err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-func list[T any](n *node[T]) (map[string]any, error) {
+func list[T any](ctx context.Context, n *node[T]) (map[string]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	result := make(map[string]any)
 	for k, v := range n.entries {
 		switch x := v.(type) {
@@ -296,7 +399,7 @@ func list[T any](n *node[T]) (map[string]any, error) {
 			result[k] = nil // nil to indicate a resource (empty map is not distinguishable from empty directory)
 		case *node[T]:
 			var err error
-			result[k], err = list(x) // recursive map to indicate a directory
+			result[k], err = list(ctx, x) // recursive map to indicate a directory
 			if err != nil {
 				return nil, err
 			}
@@ -305,6 +408,41 @@ func list[T any](n *node[T]) (map[string]any, error) {
 	return result, nil
 }
 
+func isLeaf[T any](t tree) bool {
+	_, ok := t.(*leaf[T])
+	return ok
+}
+
+// AddHook registers a function that is called with every change of the directory
+func (d *directory[T]) AddHook(hook func(directoryPkg.Event)) (remove func()) {
+	d.hooksLock.Lock()
+	defer d.hooksLock.Unlock()
+	id := d.nextHook
+	d.nextHook++
+	d.hooks[id] = hook
+	return func() {
+		d.hooksLock.Lock()
+		defer d.hooksLock.Unlock()
+		delete(d.hooks, id)
+	}
+}
+
+// Notify calls all hooks with the event while the directory is locked
+func (d *directory[T]) Notify(event directoryPkg.Event) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.notify(event)
+}
+
+// notify calls all hooks with the event (the directory must be locked)
+func (d *directory[T]) notify(event directoryPkg.Event) {
+	d.hooksLock.RLock()
+	defer d.hooksLock.RUnlock()
+	for _, hook := range d.hooks {
+		hook(event)
+	}
+}
+
 // Changes the root directory of this directory to the one of the given directory.
 // Given directory must not be the same as this directory.
 // Given directory must be of same implementation type as this directory.
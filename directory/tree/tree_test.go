@@ -0,0 +1,104 @@
+package tree_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ProjectLighthouseCAU/beacon/directory"
+	"github.com/ProjectLighthouseCAU/beacon/directory/tree"
+)
+
+func TestMove(t *testing.T) {
+	dir := tree.NewTree[*int]()
+	value := 1
+	if err := dir.CreateLeaf([]string{"a", "b"}, &value); err != nil {
+		t.Fatal(err)
+	}
+	if err := dir.Move([]string{"a"}, []string{"c", "d"}); err != nil {
+		t.Fatalf("Move failed with error: %s", err)
+	}
+	got, err := dir.GetLeaf([]string{"c", "d", "b"})
+	if err != nil || got != &value {
+		t.Fatalf("Move must keep the same value, but got %v (%v)", got, err)
+	}
+	if _, err := dir.GetLeaf([]string{"a", "b"}); err == nil {
+		t.Fatal("Move must remove the source")
+	}
+	if err := dir.Move([]string{"c"}, []string{"c", "d", "e"}); err == nil {
+		t.Fatal("Move into itself must fail")
+	}
+	if err := dir.CreateDirectory([]string{"x"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dir.Move([]string{"c"}, []string{"x"}); !errors.Is(err, directory.ErrAlreadyExists) {
+		t.Fatalf("Move to an existing path must fail with ErrAlreadyExists, but got %v", err)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	dir := tree.NewTree[*int]()
+	value := 1
+	if err := dir.CreateLeaf([]string{"a", "b"}, &value); err != nil {
+		t.Fatal(err)
+	}
+	if err := dir.CreateDirectory([]string{"a", "empty"}); err != nil {
+		t.Fatal(err)
+	}
+	var copiedPath []string
+	err := dir.Copy([]string{"a"}, []string{"c"}, func(path []string, value *int) *int {
+		copiedPath = path
+		copied := *value + 1
+		return &copied
+	})
+	if err != nil {
+		t.Fatalf("Copy failed with error: %s", err)
+	}
+	got, err := dir.GetLeaf([]string{"c", "b"})
+	if err != nil || *got != 2 || len(copiedPath) != 2 || copiedPath[0] != "c" {
+		t.Fatalf("Copy must duplicate the values with the copy function, but got %v (%v) at %v", got, err, copiedPath)
+	}
+	if original, _ := dir.GetLeaf([]string{"a", "b"}); original != &value {
+		t.Fatal("Copy must keep the source")
+	}
+	if _, err := dir.List([]string{"c", "empty"}); err != nil {
+		t.Fatalf("Copy must copy empty directories, but got %v", err)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	dir := tree.NewTree[*int]()
+	var events []directory.Event
+	remove := dir.AddHook(func(event directory.Event) {
+		events = append(events, event)
+	})
+	value := 1
+	dir.CreateLeaf([]string{"a", "b"}, &value)
+	dir.CreateDirectory([]string{"c"})
+	dir.Move([]string{"a"}, []string{"c", "a"})
+	dir.Copy([]string{"c", "a", "b"}, []string{"d"}, func(path []string, value *int) *int { return value })
+	dir.Delete([]string{"c"})
+	dir.Delete([]string{"x"}) // fails, no event
+	expected := []directory.Event{
+		{Kind: directory.Created, Path: []string{"a", "b"}, Leaf: true},
+		{Kind: directory.Created, Path: []string{"c"}},
+		{Kind: directory.Moved, Path: []string{"c", "a"}, Source: []string{"a"}},
+		{Kind: directory.Created, Path: []string{"d"}, Source: []string{"c", "a", "b"}, Leaf: true},
+		{Kind: directory.Deleted, Path: []string{"c"}},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("Hook expected events %v, but got %v", expected, events)
+	}
+	// events also affect the subtrees and ancestors of their paths
+	if !events[4].Affects([]string{"c", "a", "b"}) || !events[2].Affects([]string{"a", "b"}) || !events[0].Affects([]string{"a"}) {
+		t.Fatal("Event must affect the subtree and ancestors of its paths")
+	}
+	if events[1].Affects([]string{"d"}) || events[2].Affects([]string{"c", "b"}) {
+		t.Fatal("Event must not affect unrelated paths")
+	}
+	remove()
+	dir.CreateDirectory([]string{"e"})
+	if len(events) != len(expected) {
+		t.Fatal("Removed hook must not be called anymore")
+	}
+}
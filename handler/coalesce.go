@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/resource"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+// streamOptions returns the options of a STREAM request:
+// META MAX_RATE (maximum number of updates per second) and META LATEST (only send the latest value if the client is slower than the updates).
+// The returned META only contains the options that were set (for restoring the stream when a session is resumed).
+func streamOptions(request *types.Request) (interval time.Duration, latest bool, options types.Meta, err error) {
+	options = types.Meta{}
+	if value, ok := request.META["MAX_RATE"]; ok && value != nil {
+		var rate float64
+		switch v := value.(type) {
+		case int64:
+			rate = float64(v)
+		case uint64:
+			rate = float64(v)
+		case float64:
+			rate = v
+		case float32:
+			rate = float64(v)
+		default:
+			return 0, false, nil, fmt.Errorf("MAX_RATE must be a number of updates per second, not %T", value)
+		}
+		if rate <= 0 {
+			return 0, false, nil, fmt.Errorf("MAX_RATE must be positive")
+		}
+		interval = time.Duration(float64(time.Second) / rate)
+		options["MAX_RATE"] = value
+	}
+	if value, ok := request.META["LATEST"]; ok && value != nil {
+		latest, ok = value.(bool)
+		if !ok {
+			return 0, false, nil, fmt.Errorf("LATEST must be a bool, not %T", value)
+		}
+		options["LATEST"] = latest
+	}
+	return interval, latest, options, nil
+}
+
+// coalesce forwards the values of a stream to the returned channel, at most one value per interval (0 for no limit).
+// Values that arrive while the previous value cannot be forwarded yet (because of the interval or because the receiver is busy)
+// replace the waiting value, so the receiver always gets the latest value instead of outdated ones.
+// The returned channel is closed when the stream is closed (a waiting value is discarded).
+func coalesce(stream chan resource.Content, interval time.Duration) chan resource.Content {
+	out := make(chan resource.Content)
+	go func() {
+		defer close(out)
+		var waiting resource.Content
+		hasWaiting := false
+		var next time.Time // earliest time for forwarding the next value
+		for {
+			var outC chan resource.Content // nil (blocks) unless a value can be forwarded
+			var wait <-chan time.Time
+			if hasWaiting {
+				if delay := time.Until(next); delay > 0 {
+					wait = time.After(delay)
+				} else {
+					outC = out
+				}
+			}
+			select {
+			case value, ok := <-stream:
+				if !ok {
+					return
+				}
+				waiting, hasWaiting = value, true
+			case outC <- waiting:
+				waiting, hasWaiting = nil, false
+				next = time.Now().Add(interval)
+			case <-wait:
+			}
+		}
+	}()
+	return out
+}
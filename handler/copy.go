@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ProjectLighthouseCAU/beacon/resource"
+	"github.com/ProjectLighthouseCAU/beacon/resource/brokerless"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+// copy copies the resource or directory (recursively) at the source path (payload) to the destination path (PATH).
+// The copies are new resources with the same content (without the streams and links of the original resources).
+func (handler *Handler) copy(request *types.Request) *types.Response {
+	response := types.NewResponse().Reid(request.REID)
+	sourcePath, err := request.PayloadToPath()
+	if err != nil {
+		return response.Rnum(http.StatusBadRequest).Warning(err.Error()).Build()
+	}
+	err = handler.directory.Copy(sourcePath, request.PATH, func(path []string, resrc resource.Resource[resource.Content]) resource.Resource[resource.Content] {
+		return brokerless.Create(path, resrc.Get())
+	})
+	if err != nil {
+		return response.Warning(err.Error()).Rnum(directoryErrorCode(err)).Build()
+	}
+	return response.Rnum(http.StatusCreated).Build()
+}
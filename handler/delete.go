@@ -1,19 +1,24 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/ProjectLighthouseCAU/beacon/resource"
 	"github.com/ProjectLighthouseCAU/beacon/types"
 )
 
-func (handler *Handler) delete(request *types.Request) *types.Response {
+func (handler *Handler) delete(ctx context.Context, request *types.Request) *types.Response {
 	response := types.NewResponse().Reid(request.REID)
-	// Close all resources before deleting
-	err := handler.directory.ForEach(request.PATH, func(path []string, resource resource.Resource[resource.Content]) (bool, error) {
-		resource.Close()
+	// Collect all resources before deleting (the request can only be aborted until anything is deleted)
+	var resources []resource.Resource[resource.Content]
+	err := handler.directory.ForEach(ctx, request.PATH, func(path []string, resource resource.Resource[resource.Content]) (bool, error) {
+		resources = append(resources, resource)
 		return true, nil
 	})
+	if code, ok := contextErrorCode(err); ok {
+		return response.Warning(err.Error()).Rnum(code).Build()
+	}
 	if err != nil {
 		return response.Warning(err.Error()).Rnum(http.StatusNotFound).Build()
 	}
@@ -21,5 +26,9 @@ func (handler *Handler) delete(request *types.Request) *types.Response {
 	if err != nil {
 		return response.Warning(err.Error()).Rnum(http.StatusNotFound).Build()
 	}
+	// Close all deleted resources
+	for _, resource := range resources {
+		resource.Close()
+	}
 	return response.Rnum(http.StatusOK).Build()
 }
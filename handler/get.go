@@ -12,6 +12,6 @@ func (handler *Handler) get(request *types.Request) *types.Response {
 	if err != nil { // resource not found
 		return response.Warning(err.Error()).Rnum(http.StatusNotFound).Build()
 	}
-	payload := resource.Get()
-	return response.Rnum(http.StatusOK).Payload(payload).Build()
+	payload, version := resource.GetVersioned()
+	return response.Rnum(http.StatusOK).Meta("VERSION", version).Payload(payload).Build()
 }
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,6 +14,12 @@ import (
 	"github.com/ProjectLighthouseCAU/beacon/types"
 )
 
+// Verbs are the request methods that are handled by the handler
+var Verbs = []string{"POST", "CREATE", "MKDIR", "DELETE", "LIST", "GET", "PUT", "PATCH", "STREAM", "STOP", "LINK", "UNLINK", "MOVE", "COPY", "WATCH"}
+
+// writeVerbs are the request methods whose successful responses can be skipped with NOACK (they carry no content)
+var writeVerbs = map[string]bool{"POST": true, "CREATE": true, "MKDIR": true, "DELETE": true, "PUT": true, "PATCH": true, "LINK": true, "UNLINK": true, "MOVE": true, "COPY": true}
+
 type Handler struct {
 	directory directory.Directory[resource.Resource[resource.Content]]
 }
@@ -30,18 +38,56 @@ func (handler *Handler) GetDirectory() directory.Directory[resource.Resource[res
 }
 
 func (handler *Handler) Close() {
-	handler.directory.ForEach([]string{}, func(path []string, res resource.Resource[resource.Content]) (bool, error) {
+	handler.directory.ForEach(context.Background(), []string{}, func(path []string, res resource.Resource[resource.Content]) (bool, error) {
 		res.Close()
 		return true, nil
 	})
 }
 
-func (handler *Handler) HandleRequest(client *types.Client, request *types.Request) {
+// Locate returns the current path of a resource that was at the given path (e.g. of a stream),
+// which differs if the resource or one of its parent directories was moved in the meantime.
+// Returns the given path if the resource is not in the directory anymore.
+func (handler *Handler) Locate(ctx context.Context, path []string, resrc resource.Resource[resource.Content]) []string {
+	if current, err := handler.directory.GetLeaf(path); err == nil && current == resrc {
+		return path
+	}
+	located := path
+	handler.directory.ForEach(ctx, []string{}, func(p []string, res resource.Resource[resource.Content]) (bool, error) {
+		if res == resrc {
+			located = p
+			return false, nil
+		}
+		return true, nil
+	})
+	return located
+}
+
+// HandleRequest handles the request and sends the response to the client
+// (unless the client does not want any response to this request, e.g. fire-and-forget over UDP, or canceled it)
+func (handler *Handler) HandleRequest(ctx context.Context, client *types.Client, request *types.Request) {
+	response := handler.Process(ctx, client, request)
+	if client.NoResponse(request) || errors.Is(ctx.Err(), context.Canceled) {
+		return
+	}
+	if SkipAck(client, request, response) {
+		return
+	}
+	client.Send(response)
+}
+
+// SkipAck reports whether the response to a successful write is not sent because the client only wants to know about errors
+// (e.g. when streaming frames with PUT at a high rate)
+func SkipAck(client *types.Client, request *types.Request, response *types.Response) bool {
+	return response.RNUM < http.StatusBadRequest && writeVerbs[request.VERB] && client.NoAck(request)
+}
+
+// Process handles the request and returns the response instead of sending it.
+// Long-running operations are aborted when the context ends (see contextErrorCode).
+func (handler *Handler) Process(ctx context.Context, client *types.Client, request *types.Request) (response *types.Response) {
 	defer func() { // recover from any panic while handling the request to prevent complete server crash
 		if r := recover(); r != nil {
 			log.Println("Recovering from panic in handler:", r)
-			response := types.NewResponse().Reid(request.REID).Rnum(http.StatusInternalServerError).Warning(fmt.Sprint(r)).Build()
-			client.Send(response)
+			response = types.NewResponse().Reid(request.REID).Rnum(http.StatusInternalServerError).Warning(fmt.Sprint(r)).Build()
 		}
 	}()
 
@@ -49,13 +95,10 @@ func (handler *Handler) HandleRequest(client *types.Client, request *types.Reque
 	for _, pathElement := range request.PATH {
 		if strings.Contains(pathElement, "/") {
 			warning := "path must not contain \"/\""
-			response := types.NewResponse().Reid(request.REID).Rnum(http.StatusBadRequest).Warning(warning).Build()
-			client.Send(response)
-			return
+			return types.NewResponse().Reid(request.REID).Rnum(http.StatusBadRequest).Warning(warning).Build()
 		}
 	}
 
-	var response *types.Response
 	// create resource in case of POST
 	switch request.VERB {
 	case "POST": // POST = CREATE + PUT
@@ -65,13 +108,15 @@ func (handler *Handler) HandleRequest(client *types.Client, request *types.Reque
 	case "MKDIR":
 		response = handler.mkdir(request)
 	case "DELETE":
-		response = handler.delete(request)
+		response = handler.delete(ctx, request)
 	case "LIST":
-		response = handler.list(request)
+		response = handler.list(ctx, request)
 	case "GET":
 		response = handler.get(request)
 	case "PUT":
 		response = handler.put(request)
+	case "PATCH":
+		response = handler.patch(ctx, request)
 	case "STREAM":
 		response = handler.stream(client, request)
 	case "STOP":
@@ -80,14 +125,31 @@ func (handler *Handler) HandleRequest(client *types.Client, request *types.Reque
 		response = handler.link(request)
 	case "UNLINK":
 		response = handler.unlink(request)
+	case "MOVE": // destination: PATH, source: PAYL
+		response = handler.move(request)
+	case "COPY": // destination: PATH, source: PAYL
+		response = handler.copy(request)
+	case "WATCH":
+		response = handler.watch(client, request)
 	default:
-		response = types.NewResponse().Reid(request.REID).Rnum(http.StatusMethodNotAllowed).Build()
-		client.Send(response)
-		return
+		return types.NewResponse().Reid(request.REID).Rnum(http.StatusMethodNotAllowed).Build()
 	}
 
 	if config.VerboseLogging {
 		log.Printf("\nRequest: %+v\nResponse: %+v\n", request, response)
 	}
-	client.Send(response)
+	return response
+}
+
+// contextErrorCode returns the response code for a request that was aborted because its context ended:
+// 504 if the deadline of the request was exceeded, otherwise 408
+// (the request was canceled, so the response is usually not sent anyway)
+func contextErrorCode(err error) (int, bool) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, true
+	case errors.Is(err, context.Canceled):
+		return http.StatusRequestTimeout, true
+	}
+	return 0, false
 }
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 
+	"github.com/ProjectLighthouseCAU/beacon/directory"
 	"github.com/ProjectLighthouseCAU/beacon/resource"
 	"github.com/ProjectLighthouseCAU/beacon/types"
 )
@@ -24,6 +25,8 @@ func (handler *Handler) link(request *types.Request) *types.Response {
 	err = resrc.Link(source)
 	if err != nil {
 		response.Warning(err.Error())
+	} else {
+		handler.directory.Notify(directory.Event{Kind: directory.Linked, Path: request.PATH, Source: sourcePath, Leaf: true})
 	}
 	return response.Rnum(resource.ErrorToStatusCode(err)).Build()
 }
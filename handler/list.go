@@ -1,12 +1,13 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/ProjectLighthouseCAU/beacon/types"
 )
 
-func (handler *Handler) list(request *types.Request) *types.Response {
+func (handler *Handler) list(ctx context.Context, request *types.Request) *types.Response {
 	response := types.NewResponse().Reid(request.REID)
 	var lst types.Listing
 	var err error
@@ -17,7 +18,10 @@ func (handler *Handler) list(request *types.Request) *types.Response {
 			return response.Warning(err.Error()).Rnum(http.StatusNotFound).Build()
 		}
 	} else {
-		lst, err = handler.directory.ListRecursive(request.PATH)
+		lst, err = handler.directory.ListRecursive(ctx, request.PATH)
+		if code, ok := contextErrorCode(err); ok { // listing a huge subtree took too long
+			return response.Warning(err.Error()).Rnum(code).Build()
+		}
 		if err != nil {
 			return response.Warning(err.Error()).Rnum(http.StatusNotFound).Build()
 		}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ProjectLighthouseCAU/beacon/directory"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+// move moves the resource or directory at the source path (payload) to the destination path (PATH).
+// The resources keep their streams and links.
+func (handler *Handler) move(request *types.Request) *types.Response {
+	response := types.NewResponse().Reid(request.REID)
+	sourcePath, err := request.PayloadToPath()
+	if err != nil {
+		return response.Rnum(http.StatusBadRequest).Warning(err.Error()).Build()
+	}
+	err = handler.directory.Move(sourcePath, request.PATH)
+	if err != nil {
+		return response.Warning(err.Error()).Rnum(directoryErrorCode(err)).Build()
+	}
+	return response.Rnum(http.StatusOK).Build()
+}
+
+// directoryErrorCode returns the response code for an error of a directory operation that creates a path
+func directoryErrorCode(err error) int {
+	if errors.Is(err, directory.ErrAlreadyExists) {
+		return http.StatusConflict
+	}
+	return http.StatusNotFound
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ProjectLighthouseCAU/beacon/resource"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+// patch merges the payload (a map) into the value of the resource (see resource.Merge).
+// The merged value is written with compare-and-swap, so the merge is retried if another request changed the resource in the meantime
+// (unless the request expects a version with META IF_MATCH).
+func (handler *Handler) patch(ctx context.Context, request *types.Request) *types.Response {
+	response := types.NewResponse().Reid(request.REID)
+	ifVersion, err := ifMatch(request)
+	if err != nil {
+		return response.Warning(err.Error()).Rnum(http.StatusBadRequest).Build()
+	}
+	resrc, err := handler.directory.GetLeaf(request.PATH)
+	if err != nil { // resource not found
+		return response.Warning(err.Error()).Rnum(http.StatusNotFound).Build()
+	}
+	for {
+		if code, ok := contextErrorCode(ctx.Err()); ok {
+			return response.Warning(ctx.Err().Error()).Rnum(code).Build()
+		}
+		value, version := resrc.GetVersioned()
+		if ifVersion != nil && *ifVersion != version {
+			return response.Warning(resource.ErrVersionMismatch.Error()).Meta("VERSION", version).Rnum(http.StatusPreconditionFailed).Build()
+		}
+		merged, err := resource.Merge(value, request.PayloadToContent())
+		if err != nil {
+			return response.Warning(err.Error()).Rnum(http.StatusBadRequest).Build()
+		}
+		version, err = resrc.PutVersioned(merged, &version)
+		if err == resource.ErrVersionMismatch && ifVersion == nil { // changed by another request, merge again
+			continue
+		}
+		if err != nil {
+			response.Warning(err.Error())
+		}
+		return response.Meta("VERSION", version).Rnum(resource.ErrorToStatusCode(err)).Build()
+	}
+}
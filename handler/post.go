@@ -10,19 +10,29 @@ import (
 
 func (handler *Handler) post(request *types.Request) *types.Response {
 	response := types.NewResponse().Reid(request.REID)
-	resrc := brokerless.Create(request.PATH, request.PayloadToContent())
-	err := handler.directory.CreateLeaf(request.PATH, resrc)
-	if err == nil {
-		response.Rnum(http.StatusCreated)
-		return response.Build()
+	ifVersion, err := ifMatch(request)
+	if err != nil {
+		return response.Warning(err.Error()).Rnum(http.StatusBadRequest).Build()
+	}
+	if ifVersion == nil { // a resource is only created if no version is expected
+		resrc := brokerless.Create(request.PATH, request.PayloadToContent())
+		err = handler.directory.CreateLeaf(request.PATH, resrc)
+		if err == nil {
+			return response.Meta("VERSION", uint64(0)).Rnum(http.StatusCreated).Build()
+		}
+		// creation failed (already exists or other error)
+		response.Warning(err.Error())
 	}
-	// creation failed (already exists or other error)
-	response.Warning(err.Error()).Rnum(http.StatusOK)
-	resrc, err = handler.directory.GetLeaf(request.PATH)
-	if err != nil { // other error during creation
+	response.Rnum(http.StatusOK)
+	resrc, err := handler.directory.GetLeaf(request.PATH)
+	if err != nil { // other error during creation or the expected version does not exist
+		if ifVersion != nil {
+			return response.Warning(err.Error()).Rnum(http.StatusPreconditionFailed).Build()
+		}
 		return response.Warning(err.Error()).Rnum(http.StatusNotFound).Build()
 	}
-	err = resrc.Put(request.PayloadToContent())
+	version, err := resrc.PutVersioned(request.PayloadToContent(), ifVersion)
+	response.Meta("VERSION", version)
 	if err != nil {
 		return response.Warning(err.Error()).Rnum(resource.ErrorToStatusCode(err)).Build()
 	}
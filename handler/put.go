@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/ProjectLighthouseCAU/beacon/resource"
@@ -9,13 +10,39 @@ import (
 
 func (handler *Handler) put(request *types.Request) *types.Response {
 	response := types.NewResponse().Reid(request.REID)
+	ifVersion, err := ifMatch(request)
+	if err != nil {
+		return response.Warning(err.Error()).Rnum(http.StatusBadRequest).Build()
+	}
 	resrc, err := handler.directory.GetLeaf(request.PATH)
 	if err != nil { // resource not found
 		return response.Warning(err.Error()).Rnum(http.StatusNotFound).Build()
 	}
-	err = resrc.Put(request.PayloadToContent())
+	version, err := resrc.PutVersioned(request.PayloadToContent(), ifVersion)
 	if err != nil {
 		response.Warning(err.Error())
 	}
-	return response.Rnum(resource.ErrorToStatusCode(err)).Build()
+	return response.Meta("VERSION", version).Rnum(resource.ErrorToStatusCode(err)).Build()
+}
+
+// ifMatch returns the version that the resource must have for a PUT, PATCH or POST request to succeed (META IF_MATCH),
+// nil if the request does not expect a version
+func ifMatch(request *types.Request) (*uint64, error) {
+	value, ok := request.META["IF_MATCH"]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	var version uint64
+	switch v := value.(type) {
+	case uint64:
+		version = v
+	case int64:
+		if v < 0 {
+			return nil, fmt.Errorf("IF_MATCH must not be negative")
+		}
+		version = uint64(v)
+	default:
+		return nil, fmt.Errorf("IF_MATCH must be a version number, not %T", value)
+	}
+	return &version, nil
 }
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ProjectLighthouseCAU/beacon/directory/tree"
+	"github.com/ProjectLighthouseCAU/beacon/resource"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+	"github.com/tinylib/msgp/msgp"
+)
+
+func TestIfMatch(t *testing.T) {
+	handler := New(tree.NewTree[resource.Resource[resource.Content]]())
+	client := types.NewClient("client", nil)
+	payload := func(value int) msgp.Raw {
+		return msgp.AppendInt(msgp.AppendString(msgp.AppendMapHeader(nil, 1), "a"), value)
+	}
+	tests := []struct {
+		verb    string
+		path    []string
+		ifMatch any
+		rnum    int
+		version any
+	}{
+		{"POST", []string{"x"}, nil, http.StatusCreated, uint64(0)},
+		{"PUT", []string{"x"}, uint64(0), http.StatusOK, uint64(1)},
+		{"PUT", []string{"x"}, uint64(0), http.StatusPreconditionFailed, uint64(1)},
+		{"PATCH", []string{"x"}, int64(0), http.StatusPreconditionFailed, uint64(1)},
+		{"POST", []string{"x"}, uint64(0), http.StatusPreconditionFailed, uint64(1)},
+		{"PUT", []string{"x"}, int64(-1), http.StatusBadRequest, nil},
+		{"POST", []string{"y"}, uint64(0), http.StatusPreconditionFailed, nil}, // not created if a version is expected
+		{"PATCH", []string{"x"}, int64(1), http.StatusOK, uint64(2)},
+	}
+	for i, test := range tests {
+		request := &types.Request{REID: msgp.AppendInt(nil, i), VERB: test.verb, PATH: test.path, META: types.Meta{}, PAYL: payload(i)}
+		if test.ifMatch != nil {
+			request.META["IF_MATCH"] = test.ifMatch
+		}
+		response := handler.Process(context.Background(), client, request)
+		if response.RNUM != test.rnum || response.META["VERSION"] != test.version {
+			t.Fatalf("%s %v with IF_MATCH %v: expected %d (version %v), got %d (version %v)",
+				test.verb, test.path, test.ifMatch, test.rnum, test.version, response.RNUM, response.META["VERSION"])
+		}
+	}
+	if _, err := handler.directory.GetLeaf([]string{"y"}); err == nil {
+		t.Fatal("resource was created although a version was expected")
+	}
+
+	resrc, _ := handler.directory.GetLeaf([]string{"x"})
+	value, version := resrc.GetVersioned()
+	if version != 2 || !bytes.Equal(value, payload(len(tests)-1)) {
+		t.Fatalf("expected the value of the last successful write with version 2, got %v (version %d)", value, version)
+	}
+}
@@ -11,16 +11,19 @@ import (
 
 func (handler *Handler) stop(client *types.Client, request *types.Request) *types.Response {
 	response := types.NewResponse().Reid(request.REID)
-	resrc, err := handler.directory.GetLeaf(request.PATH)
-	if err != nil { // resource not found
-		return response.Warning(err.Error()).Rnum(http.StatusNotFound).Build()
-	}
-	stream := client.GetStream(request.REID, request.PATH)
+	// the stream is identified by the PATH of the STREAM request, even if the resource was moved in the meantime
+	stream, resrc := client.GetStream(request.REID, request.PATH)
 	if stream == nil {
+		if client.StopWatch(request.REID, request.PATH) { // WATCH requests are stopped the same way
+			return response.Rnum(http.StatusOK).Build()
+		}
+		if _, err := handler.directory.GetLeaf(request.PATH); err != nil { // resource not found
+			return response.Warning(err.Error()).Rnum(http.StatusNotFound).Build()
+		}
 		warning := fmt.Sprintf("No open stream for resource %s with REID %v", strings.Join(request.PATH, "/"), request.REID)
 		return response.Rnum(http.StatusNotFound).Warning(warning).Build()
 	}
-	err = resrc.StopStream(stream)
+	err := resrc.StopStream(stream)
 	if err != nil {
 		response.Warning(err.Error())
 	}
@@ -16,29 +16,42 @@ func (handler *Handler) stream(client *types.Client, request *types.Request) *ty
 	}
 
 	// stream with this REID on this PATH already exists
-	if s := client.GetStream(request.REID, request.PATH); s != nil {
+	if s, _ := client.GetStream(request.REID, request.PATH); s != nil {
 		// don't open another stream, only return resource content
-		payload := resource.Get()
-		response.Warning(fmt.Sprintf("Already streaming %s", strings.Join(request.PATH, "/")))
+		payload, version := resource.GetVersioned()
+		response.Meta("VERSION", version).Warning(fmt.Sprintf("Already streaming %s", strings.Join(request.PATH, "/")))
 		return response.Rnum(http.StatusOK).Payload(payload).Build()
 	}
 
+	interval, latest, options, err := streamOptions(request)
+	if err != nil {
+		return response.Warning(err.Error()).Rnum(http.StatusBadRequest).Build()
+	}
+
 	// create stream channel and add it to the client
 	stream := resource.Stream()
-	client.AddStream(request.REID, request.PATH, stream)
+	client.AddStream(request.REID, request.PATH, stream, resource, options)
+	updates := stream
+	if interval > 0 || latest { // throttle and coalesce the updates before sending them
+		updates = coalesce(stream, interval)
+	}
 	// start goroutine for sending updates
 	go func() {
 		streamResponse := types.NewResponse().Reid(request.REID).Rnum(http.StatusOK)
-		for payload := range stream {
+		for payload := range updates {
 			streamResponse.Payload(payload).Build()
 			err := client.Send(streamResponse)
 			if err != nil { // client closed
-				resource.StopStream(stream)
+				resource.StopStream(stream) // also ends the coalescing of the updates
 				return
 			}
 		}
 	}()
-	// return resource content
-	payload := resource.Get()
-	return response.Rnum(http.StatusOK).Payload(payload).Build()
+	// return resource content with its version (and the session for resuming the stream after reconnecting).
+	// The updates do not contain the version since it can change again before an update is sent.
+	if session := client.Session(); session != "" {
+		response.Meta("SESSION", session)
+	}
+	payload, version := resource.GetVersioned()
+	return response.Rnum(http.StatusOK).Meta("VERSION", version).Payload(payload).Build()
 }
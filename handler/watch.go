@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/directory"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// watch sends the changes of the directory at the path, its subtree and its ancestors as responses with the REID of the WATCH request until it is stopped with STOP.
+// The payload of every response is an event, e.g. {"EVENT": "MOVED", "PATH": ["a", "c"], "TYPE": "RESOURCE", "SOURCE": ["a", "b"]}.
+// The path does not need to exist, so clients can wait for resources to be created.
+func (handler *Handler) watch(client *types.Client, request *types.Request) *types.Response {
+	response := types.NewResponse().Reid(request.REID)
+	events := make(chan directory.Event, config.WatchChannelSize)
+	remove := handler.directory.AddHook(func(event directory.Event) {
+		if !event.Affects(request.PATH) {
+			return
+		}
+		select { // the directory must not be blocked by slow clients
+		case events <- event:
+		default:
+			log.Println("[Warning] A watch channel is full and an event was skipped")
+		}
+	})
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			remove()
+			close(events)
+		})
+	}
+	if !client.AddWatch(request.REID, request.PATH, stop) {
+		stop()
+		response.Warning(fmt.Sprintf("Already watching %s", strings.Join(request.PATH, "/")))
+		return response.Rnum(http.StatusOK).Build()
+	}
+	// start goroutine for sending the events
+	go func() {
+		for event := range events {
+			event, ok := visibleEvent(client, request, event)
+			if !ok {
+				continue
+			}
+			eventResponse := types.NewResponse().Reid(request.REID).Rnum(http.StatusOK).Payload(encodeEvent(event)).Build()
+			if err := client.Send(eventResponse); err != nil { // client closed
+				client.StopWatch(request.REID, request.PATH)
+			}
+		}
+	}()
+	return response.Rnum(http.StatusOK).Build()
+}
+
+// visibleEvent restricts an event to the paths that the client is authorized to watch (e.g. with a subtree auth).
+// Moving a path out of or into the visible paths is reported as deleting or creating it, other paths are removed from the event.
+// Returns false if the client must not receive the event at all.
+func visibleEvent(client *types.Client, request *types.Request, event directory.Event) (directory.Event, bool) {
+	visible := func(path []string) bool {
+		return client.Authorized(&types.Request{REID: request.REID, AUTH: request.AUTH, VERB: "WATCH", PATH: path, META: request.META})
+	}
+	pathVisible := visible(event.Path)
+	sourceVisible := event.Source != nil && visible(event.Source)
+	switch {
+	case pathVisible && (event.Source == nil || sourceVisible):
+		return event, true
+	case pathVisible:
+		if event.Kind == directory.Moved {
+			event.Kind = directory.Created
+		}
+		event.Source = nil
+		return event, true
+	case sourceVisible && event.Kind == directory.Moved:
+		return directory.Event{Kind: directory.Deleted, Path: event.Source, Leaf: event.Leaf}, true
+	default:
+		return event, false
+	}
+}
+
+func encodeEvent(event directory.Event) []byte {
+	size := uint32(3)
+	if event.Source != nil {
+		size++
+	}
+	b := msgp.AppendMapHeader(nil, size)
+	b = msgp.AppendString(b, "EVENT")
+	b = msgp.AppendString(b, string(event.Kind))
+	b = msgp.AppendString(b, "PATH")
+	b = appendPath(b, event.Path)
+	b = msgp.AppendString(b, "TYPE")
+	if event.Leaf {
+		b = msgp.AppendString(b, "RESOURCE")
+	} else {
+		b = msgp.AppendString(b, "DIRECTORY")
+	}
+	if event.Source != nil {
+		b = msgp.AppendString(b, "SOURCE")
+		b = appendPath(b, event.Source)
+	}
+	return b
+}
+
+func appendPath(b []byte, path []string) []byte {
+	b = msgp.AppendArrayHeader(b, uint32(len(path)))
+	for _, element := range path {
+		b = msgp.AppendString(b, element)
+	}
+	return b
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/ProjectLighthouseCAU/beacon/directory"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+func TestVisibleEvent(t *testing.T) {
+	client := types.NewClient("client", nil)
+	client.SetAuthorizer(func(request *types.Request) bool { // only the subtree "public" is visible
+		return len(request.PATH) > 0 && request.PATH[0] == "public"
+	})
+	request := &types.Request{VERB: "WATCH", PATH: []string{}}
+	tests := []struct {
+		event    directory.Event
+		expected directory.Event
+		visible  bool
+	}{
+		{
+			directory.Event{Kind: directory.Moved, Path: []string{"public", "a"}, Source: []string{"public", "b"}},
+			directory.Event{Kind: directory.Moved, Path: []string{"public", "a"}, Source: []string{"public", "b"}}, true,
+		},
+		{ // moved into the subtree
+			directory.Event{Kind: directory.Moved, Path: []string{"public", "a"}, Source: []string{"private", "a"}, Leaf: true},
+			directory.Event{Kind: directory.Created, Path: []string{"public", "a"}, Leaf: true}, true,
+		},
+		{ // moved out of the subtree
+			directory.Event{Kind: directory.Moved, Path: []string{"private", "a"}, Source: []string{"public", "a"}, Leaf: true},
+			directory.Event{Kind: directory.Deleted, Path: []string{"public", "a"}, Leaf: true}, true,
+		},
+		{ // linked to a resource outside of the subtree
+			directory.Event{Kind: directory.Linked, Path: []string{"public", "a"}, Source: []string{"private", "a"}, Leaf: true},
+			directory.Event{Kind: directory.Linked, Path: []string{"public", "a"}, Leaf: true}, true,
+		},
+		{
+			directory.Event{Kind: directory.Linked, Path: []string{"private", "a"}, Source: []string{"public", "a"}, Leaf: true},
+			directory.Event{}, false,
+		},
+		{
+			directory.Event{Kind: directory.Deleted, Path: []string{"private"}},
+			directory.Event{}, false,
+		},
+	}
+	for _, test := range tests {
+		event, visible := visibleEvent(client, request, test.event)
+		if visible != test.visible || (visible && !reflect.DeepEqual(event, test.expected)) {
+			t.Fatalf("Event %v expected %v (visible: %t), but got %v (visible: %t)", test.event, test.expected, test.visible, event, visible)
+		}
+		if visible && (!slices.Equal(event.Path[:1], []string{"public"}) || (event.Source != nil && event.Source[0] != "public")) {
+			t.Fatalf("Event %v contains paths outside of the visible subtree", event)
+		}
+	}
+}
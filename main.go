@@ -13,10 +13,16 @@ import (
 	"github.com/ProjectLighthouseCAU/beacon/auth/heimdall"
 	"github.com/ProjectLighthouseCAU/beacon/auth/legacy"
 	"github.com/ProjectLighthouseCAU/beacon/cli"
+	"github.com/ProjectLighthouseCAU/beacon/directory"
 	"github.com/ProjectLighthouseCAU/beacon/directory/tree"
 	"github.com/ProjectLighthouseCAU/beacon/handler"
 	"github.com/ProjectLighthouseCAU/beacon/network"
+	"github.com/ProjectLighthouseCAU/beacon/network/rest"
+	"github.com/ProjectLighthouseCAU/beacon/network/tcp"
+	"github.com/ProjectLighthouseCAU/beacon/network/udp"
+	"github.com/ProjectLighthouseCAU/beacon/network/unix"
 	"github.com/ProjectLighthouseCAU/beacon/network/websocket"
+	"github.com/ProjectLighthouseCAU/beacon/ratelimit"
 	"github.com/ProjectLighthouseCAU/beacon/resource"
 	"github.com/ProjectLighthouseCAU/beacon/snapshot"
 	"github.com/ProjectLighthouseCAU/beacon/static"
@@ -44,7 +50,7 @@ func main() {
 	// defer pprof.WriteHeapProfile(f)
 
 	// ### STARTUP ###
-	log.Println("Starting server...")
+	log.Printf("Starting server (version %s)...\n", config.Version)
 
 	log.Printf("GOMAXPROCS: %d\n", runtime.GOMAXPROCS(0))
 
@@ -67,24 +73,43 @@ func main() {
 		panic(err)
 	}
 
-	var authImpl auth.Auth
-	switch config.Auth {
-	case "hardcoded":
-		authImpl = hardcoded.New()
-	case "legacy":
-		authImpl = legacy.New(directory)
-	case "allow_all":
-		authImpl = auth.AllowAll()
-	case "allow_none":
-		authImpl = auth.AllowNone()
-	case "heimdall":
-		authImpl = heimdall.New(directory)
+	// auth implementations are created once and shared by all endpoints (and websocket routes) that use them
+	authImpls := make(map[string]auth.Auth)
+	getAuth := func(name string) auth.Auth {
+		if authImpl, ok := authImpls[name]; ok {
+			return authImpl
+		}
+		authImpl := createAuth(name, directory)
+		authImpls[name] = authImpl
+		return authImpl
 	}
+	authImpl := getAuth(config.Auth)
 
 	handler := handler.New(directory)
 
-	websocketEndpoint := websocket.CreateEndpoint(config.WebsocketHost, config.WebsocketPort, authImpl, handler)
-	endpoints := []network.Endpoint{websocketEndpoint}
+	limiter := ratelimit.New(ratelimit.ParseConfig(config.RateLimitsJson))
+
+	var endpoints []network.Endpoint
+	for _, endpointName := range config.Endpoints {
+		switch endpointName {
+		case "websocket":
+			var routes []websocket.Route
+			for _, routeConfig := range websocket.RouteConfigs() {
+				routes = append(routes, routeConfig.Route(getAuth))
+			}
+			endpoints = append(endpoints, websocket.CreateEndpoint(config.WebsocketHost, config.WebsocketPort, routes, limiter, handler))
+		case "tcp":
+			endpoints = append(endpoints, tcp.CreateEndpoint(config.TcpHost, config.TcpPort, authImpl, limiter, handler))
+		case "unix":
+			endpoints = append(endpoints, unix.CreateEndpoint(config.UnixSocketPath, config.UnixSocketMode, authImpl, limiter, handler))
+		case "udp":
+			endpoints = append(endpoints, udp.CreateEndpoint(config.UdpHost, config.UdpPort, authImpl, limiter, handler))
+		case "rest":
+			endpoints = append(endpoints, rest.CreateEndpoint(config.RestHost, config.RestPort, authImpl, limiter, handler))
+		default:
+			log.Printf("Unknown endpoint %q in ENDPOINTS, skipping it\n", endpointName)
+		}
+	}
 
 	static.StartFileserver()
 
@@ -119,3 +144,27 @@ func main() {
 	snapshotter.StopAndWait()
 	log.Println("Server stopped")
 }
+
+// createAuth creates the auth implementation with the given name (see config.Auth)
+func createAuth(name string, directory directory.Directory[resource.Resource[resource.Content]]) auth.Auth {
+	var authImpl auth.Auth
+	switch name {
+	case "hardcoded":
+		authImpl = hardcoded.New()
+	case "legacy":
+		authImpl = legacy.New(directory)
+	case "allow_all":
+		authImpl = auth.AllowAll()
+	case "allow_none":
+		authImpl = auth.AllowNone()
+	case "heimdall":
+		authImpl = heimdall.New(directory)
+	default:
+		log.Printf("Unknown auth %q, allowing no requests\n", name)
+		authImpl = auth.AllowNone()
+	}
+	if config.TlsClientCaFile != "" {
+		authImpl = auth.NewCertificateAuth(authImpl)
+	}
+	return authImpl
+}
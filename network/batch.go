@@ -0,0 +1,130 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/handler"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+	"github.com/tinylib/msgp/msgp"
+)
+
+var errBatchTooLarge = errors.New("too many requests in batch")
+
+// handleBatch processes the items of a batch request (PAYL: array of requests) in order
+// through the normal auth and handler path. Items without AUTH inherit the AUTH of the batch request.
+// The responses of the items are returned as an array in the PAYL of the batch response
+// or sent individually before the batch response if META INDIVIDUAL is true.
+// If META ABORT_ON_ERROR is true, the items after the first failed item are not processed (424 Failed Dependency).
+// If the deadline of the batch is exceeded, the remaining items are not processed (504), if the batch is canceled, nothing more is sent.
+// Returns whether any item was authorized.
+func (ep *BaseEndpoint) handleBatch(ctx context.Context, client *types.Client, batch *types.Request) bool {
+	noResponse := client.NoResponse(batch)
+	individual, _ := batch.META["INDIVIDUAL"].(bool)
+	abortOnError, _ := batch.META["ABORT_ON_ERROR"].(bool)
+	send := func(response *types.Response) {
+		if !noResponse {
+			client.Send(response)
+		}
+	}
+
+	items, err := decodeBatch(batch.PAYL)
+	if err != nil {
+		rnum := http.StatusBadRequest
+		if errors.Is(err, errBatchTooLarge) {
+			rnum = http.StatusRequestEntityTooLarge
+		}
+		send(types.NewResponse().Reid(batch.REID).Rnum(rnum).Warning(err.Error()).Build())
+		return false
+	}
+
+	responses := make([]*types.Response, 0, len(items))
+	anyAuthorized, anyFailed := false, false
+	for i := range items {
+		item := &items[i]
+		if len(item.AUTH) == 0 {
+			item.AUTH = maps.Clone(batch.AUTH)
+		}
+		if item.META == nil {
+			item.META = types.Meta{}
+		}
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return anyAuthorized
+		}
+		var response *types.Response
+		switch {
+		case ctx.Err() != nil:
+			response = types.NewResponse().Reid(item.REID).Rnum(http.StatusGatewayTimeout).Warning("deadline of the batch exceeded").Build()
+		case anyFailed && abortOnError:
+			response = types.NewResponse().Reid(item.REID).Rnum(http.StatusFailedDependency).Warning("not processed because a previous request of the batch failed").Build()
+		case item.VERB == "BATCH":
+			response = types.NewResponse().Reid(item.REID).Rnum(http.StatusBadRequest).Warning("nested batch requests are not supported").Build()
+		default:
+			var authorized bool
+			response, authorized = ep.authorize(client, item)
+			anyAuthorized = anyAuthorized || authorized
+			if response == nil {
+				response = ep.Handler.Process(ctx, client, item)
+			}
+		}
+		if response.RNUM >= http.StatusBadRequest {
+			anyFailed = true
+		}
+		if !individual {
+			responses = append(responses, response)
+		} else if !client.NoResponse(item) && !handler.SkipAck(client, item, response) {
+			send(response)
+		}
+	}
+
+	response := types.NewResponse().Reid(batch.REID).Rnum(http.StatusOK)
+	if anyFailed {
+		response.Rnum(http.StatusMultiStatus)
+	}
+	if !individual {
+		payload, err := encodeBatchResponses(responses)
+		if err != nil {
+			send(types.NewResponse().Reid(batch.REID).Rnum(http.StatusInternalServerError).Warning(err.Error()).Build())
+			return anyAuthorized
+		}
+		response.Payload(payload)
+	}
+	send(response.Build())
+	return anyAuthorized
+}
+
+// decodeBatch decodes the requests of a batch (at most config.BatchMaxSize)
+func decodeBatch(payload msgp.Raw) ([]types.Request, error) {
+	n, rest, err := msgp.ReadArrayHeaderBytes(payload)
+	if err != nil {
+		return nil, fmt.Errorf("payload of a batch must be an array of requests: %w", err)
+	}
+	if int(n) > config.BatchMaxSize {
+		return nil, fmt.Errorf("%w (%d > %d)", errBatchTooLarge, n, config.BatchMaxSize)
+	}
+	items := make([]types.Request, n)
+	for i := range items {
+		rest, err = items[i].UnmarshalMsg(rest)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode request %d of batch: %w", i, err)
+		}
+	}
+	return items, nil
+}
+
+// encodeBatchResponses encodes the responses of the items of a batch as an array
+func encodeBatchResponses(responses []*types.Response) ([]byte, error) {
+	payload := msgp.AppendArrayHeader(nil, uint32(len(responses)))
+	var err error
+	for _, response := range responses {
+		payload, err = response.MarshalMsg(payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return payload, nil
+}
@@ -0,0 +1,114 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/types"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// RequestContext returns the context for processing a request, which ends with the parent context
+// or when the deadline of the request (META DEADLINE in milliseconds after receiving the request) is exceeded.
+// Returns an error if the deadline is not a number.
+func RequestContext(parent context.Context, request *types.Request) (context.Context, context.CancelFunc, error) {
+	deadline, ok := request.META["DEADLINE"]
+	if !ok || deadline == nil {
+		ctx, cancel := context.WithCancel(parent)
+		return ctx, cancel, nil
+	}
+	var timeout time.Duration
+	switch d := deadline.(type) {
+	case int64:
+		timeout = time.Duration(d) * time.Millisecond
+	case uint64:
+		timeout = time.Duration(d) * time.Millisecond
+	case float64:
+		timeout = time.Duration(d * float64(time.Millisecond))
+	case float32:
+		timeout = time.Duration(float64(d) * float64(time.Millisecond))
+	default:
+		return nil, nil, fmt.Errorf("DEADLINE must be a number of milliseconds, not %T", deadline)
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	return ctx, cancel, nil
+}
+
+// inflightRequests are the requests of a connection that are queued or being processed, so they can be canceled by their REID
+type inflightRequests struct {
+	lock     sync.Mutex
+	requests map[string]map[*inflightRequest]struct{} // REID -> requests (REIDs are not necessarily unique)
+}
+
+// inflightRequest is a received request with the context for processing it
+type inflightRequest struct {
+	request *types.Request
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+func newInflightRequests() *inflightRequests {
+	return &inflightRequests{requests: make(map[string]map[*inflightRequest]struct{})}
+}
+
+// add creates the context of a received request and keeps it until done is called
+func (r *inflightRequests) add(parent context.Context, request *types.Request) (*inflightRequest, error) {
+	ctx, cancel, err := RequestContext(parent, request)
+	if err != nil {
+		return nil, err
+	}
+	inflight := &inflightRequest{request: request, ctx: ctx, cancel: cancel}
+	reid := string(request.REID)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.requests[reid] == nil {
+		r.requests[reid] = make(map[*inflightRequest]struct{})
+	}
+	r.requests[reid][inflight] = struct{}{}
+	return inflight, nil
+}
+
+// done removes a processed request and releases its context
+func (r *inflightRequests) done(inflight *inflightRequest) {
+	inflight.cancel()
+	reid := string(inflight.request.REID)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.requests[reid], inflight)
+	if len(r.requests[reid]) == 0 {
+		delete(r.requests, reid)
+	}
+}
+
+// cancel cancels all queued or running requests with the given REID and returns how many were canceled
+func (r *inflightRequests) cancel(reid msgp.Raw) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	requests := r.requests[string(reid)]
+	for inflight := range requests {
+		inflight.cancel()
+	}
+	return len(requests)
+}
+
+// handleCancel answers a CANCEL request, which cancels the queued or running requests of the client
+// with the REID in the payload (canceled requests are not answered).
+// CANCEL requires no authorization since it only affects the requests of the same connection.
+func (ep *BaseEndpoint) handleCancel(client *types.Client, request *types.Request, inflight *inflightRequests) {
+	response := types.NewResponse().Reid(request.REID)
+	if ok, retryAfter := ep.RateLimiter.Allow(client, request); !ok {
+		response.Rnum(http.StatusTooManyRequests).Meta("RETRY_AFTER", retryAfter.Milliseconds())
+	} else if len(request.PAYL) == 0 {
+		response.Rnum(http.StatusBadRequest).Warning("payload of CANCEL must be the REID of the request to cancel")
+	} else if canceled := inflight.cancel(request.PAYL); canceled == 0 {
+		response.Rnum(http.StatusNotFound).Warning("no queued or running request with this REID")
+	} else {
+		response.Rnum(http.StatusOK).Meta("CANCELED", canceled)
+	}
+	if !client.NoResponse(request) {
+		client.Send(response.Build())
+	}
+}
@@ -0,0 +1,19 @@
+package network
+
+// CloseCode describes why a connection is closed by the server.
+// The values are the websocket close codes defined in RFC 6455,
+// transports without close messages (e.g. TCP) ignore them.
+type CloseCode int
+
+const (
+	CloseNormalClosure           CloseCode = 1000
+	CloseGoingAway               CloseCode = 1001
+	CloseProtocolError           CloseCode = 1002
+	CloseUnsupportedData         CloseCode = 1003
+	CloseInvalidFramePayloadData CloseCode = 1007
+	ClosePolicyViolation         CloseCode = 1008
+	CloseMessageTooBig           CloseCode = 1009
+	CloseInternalServerErr       CloseCode = 1011
+	CloseServiceRestart          CloseCode = 1012
+	CloseTryAgainLater           CloseCode = 1013
+)
@@ -0,0 +1,72 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+)
+
+var (
+	// returned by AcquireConnection if config.MaxConnections is reached
+	ErrTooManyConnections = errors.New("too many connections")
+	// returned by AcquireConnection if config.MaxConnectionsPerIp is reached for the IP address
+	ErrTooManyConnectionsFromIp = errors.New("too many connections from this IP address")
+)
+
+// counts the concurrent connections of all endpoints (in total and per IP address)
+var connectionCount = struct {
+	lock  sync.Mutex
+	total int
+	perIp map[string]int
+}{perIp: make(map[string]int)}
+
+// AcquireConnection counts a new connection of the client unless the maximum number of connections
+// (in total or from the IP address of the client) is reached.
+// Every successfully acquired connection must be released with ReleaseConnection.
+func AcquireConnection(clientIp string) error {
+	ip := stripPort(clientIp)
+	connectionCount.lock.Lock()
+	defer connectionCount.lock.Unlock()
+	if config.MaxConnections > 0 && connectionCount.total >= config.MaxConnections {
+		return ErrTooManyConnections
+	}
+	if config.MaxConnectionsPerIp > 0 && connectionCount.perIp[ip] >= config.MaxConnectionsPerIp {
+		return ErrTooManyConnectionsFromIp
+	}
+	connectionCount.total++
+	connectionCount.perIp[ip]++
+	return nil
+}
+
+// ReleaseConnection counts a closed connection that was acquired with AcquireConnection
+func ReleaseConnection(clientIp string) {
+	ip := stripPort(clientIp)
+	connectionCount.lock.Lock()
+	defer connectionCount.lock.Unlock()
+	connectionCount.total--
+	connectionCount.perIp[ip]--
+	if connectionCount.perIp[ip] <= 0 {
+		delete(connectionCount.perIp, ip)
+	}
+}
+
+// RejectConnection answers an HTTP request that opens a connection with a suitable status code for the error of AcquireConnection
+func RejectConnection(w http.ResponseWriter, err error) {
+	status := http.StatusServiceUnavailable
+	if errors.Is(err, ErrTooManyConnectionsFromIp) {
+		status = http.StatusTooManyRequests
+	}
+	http.Error(w, err.Error(), status)
+}
+
+// stripPort returns the host of a "host:port" address (or the address itself if it has no port)
+func stripPort(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
@@ -0,0 +1,175 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/handler"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// ProtocolVersion is the latest version of the Lighthouse protocol that is supported by the server
+const ProtocolVersion = 1
+
+// verbs that are handled by the request pipeline instead of the handler
+var pipelineVerbs = []string{"BATCH", "HELLO", "CANCEL"}
+
+// META keys of requests that are interpreted by the server
+var metaKeys = []string{"NONRECURSIVE", "NORESPONSE", "SESSION", "INDIVIDUAL", "ABORT_ON_ERROR", "DEADLINE", "NOACK", "IF_MATCH", "VERSION", "MAX_RATE", "LATEST"}
+
+// handleHello answers a HELLO request with the capability document of the server.
+// The client can declare its protocol version and enable or disable features for its connection
+// in the payload of the request, e.g. {"PROTOCOL": 1, "FEATURES": {"JSON": true}}.
+// HELLO requires no authorization (but is rate limited), so it does not count as authorized request.
+func (ep *BaseEndpoint) handleHello(client *types.Client, request *types.Request) bool {
+	response := types.NewResponse().Reid(request.REID).Rnum(http.StatusOK)
+	if ok, retryAfter := ep.RateLimiter.Allow(client, request); !ok {
+		response.Rnum(http.StatusTooManyRequests).Meta("RETRY_AFTER", retryAfter.Milliseconds())
+	} else if err := ep.declare(client, request.PAYL, response); err != nil {
+		response.Rnum(http.StatusBadRequest).Warning(err.Error())
+	} else {
+		payload, err := msgp.AppendIntf(nil, ep.capabilities(client))
+		if err != nil {
+			log.Println("Could not encode capabilities:", err)
+			response.Rnum(http.StatusInternalServerError)
+		}
+		response.Payload(payload)
+	}
+	if !client.NoResponse(request) {
+		client.Send(response.Build())
+	}
+	return false
+}
+
+// declare applies the protocol version and features declared in the payload of a HELLO request.
+// Ignored declarations are reported as warnings of the response.
+func (ep *BaseEndpoint) declare(client *types.Client, payload msgp.Raw, response *types.Response) error {
+	if len(payload) == 0 || msgp.IsNil(payload) {
+		return nil
+	}
+	declaration, _, err := msgp.ReadMapStrIntfBytes(payload, nil)
+	if err != nil {
+		return fmt.Errorf("payload of HELLO must be a map: %w", err)
+	}
+
+	if value, ok := declaration["PROTOCOL"]; ok {
+		version, ok := toInt(value)
+		if !ok || version < 1 {
+			return fmt.Errorf("invalid protocol version %v", value)
+		}
+		if version > ProtocolVersion {
+			response.Warning(fmt.Sprintf("protocol version %d is not supported, using %d", version, ProtocolVersion))
+			version = ProtocolVersion
+		}
+		client.SetProtocolVersion(version)
+	}
+
+	features, ok := declaration["FEATURES"].(map[string]any)
+	if !ok && declaration["FEATURES"] != nil {
+		return fmt.Errorf("FEATURES must be a map of feature names to booleans")
+	}
+	for feature, value := range features {
+		enabled, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("feature %s must be enabled with true or disabled with false", feature)
+		}
+		if !slices.Contains(ep.features(client), feature) {
+			response.Warning(fmt.Sprintf("feature %s is not supported by this endpoint", feature))
+			continue
+		}
+		client.SetFeature(feature, enabled)
+		if feature == types.FeatureJSON {
+			// the response to the HELLO request is already encoded with the new encoding
+			ep.encodingConn(client).SetJSON(enabled)
+		}
+	}
+	return nil
+}
+
+// features returns the features of HELLO that are supported by the connection of the client
+func (ep *BaseEndpoint) features(client *types.Client) []string {
+	features := []string{types.FeatureNoResponse, types.FeatureNoAck}
+	if ep.encodingConn(client) != nil {
+		features = append(features, types.FeatureJSON)
+	}
+	return features
+}
+
+// capabilities returns the capability document that answers a HELLO request
+func (ep *BaseEndpoint) capabilities(client *types.Client) map[string]any {
+	limits := map[string]any{
+		"BATCH_MAX_SIZE":     config.BatchMaxSize,
+		"SEND_QUEUE_SIZE":    config.SendQueueSize,
+		"REQUEST_QUEUE_SIZE": config.RequestQueueSize,
+	}
+	if readLimit := ep.readLimit(); readLimit > 0 {
+		limits["READ_LIMIT"] = readLimit
+	}
+	if config.SessionGracePeriod > 0 {
+		limits["SESSION_GRACE_PERIOD"] = config.SessionGracePeriod.Milliseconds()
+	}
+	return map[string]any{
+		"SERVER":   "beacon",
+		"VERSION":  config.Version,
+		"PROTOCOL": ProtocolVersion,
+		"VERBS":    toIntfSlice(slices.Concat(handler.Verbs, pipelineVerbs)),
+		"META":     toIntfSlice(metaKeys),
+		"FEATURES": toIntfSlice(ep.features(client)),
+		"LIMITS":   limits,
+		"CLIENT": map[string]any{
+			"PROTOCOL": client.ProtocolVersion(),
+			"FEATURES": toIntfSlice(client.Features()),
+		},
+	}
+}
+
+// readLimit returns the maximum size of a request on this endpoint in bytes (0 if unknown)
+func (ep *BaseEndpoint) readLimit() int {
+	switch ep.Type {
+	case Websocket:
+		return config.WebsocketReadLimit
+	case TCP:
+		return config.TcpReadLimit
+	case UNIX_DOMAIN:
+		return config.UnixSocketReadLimit
+	case UDP:
+		return config.UdpReadLimit
+	case HTTP:
+		return config.RestReadLimit
+	}
+	return 0
+}
+
+// encodingConn returns the connection of a client if it can switch its encoding (otherwise nil)
+func (ep *BaseEndpoint) encodingConn(client *types.Client) EncodingConn {
+	ep.connectionsLock.Lock()
+	defer ep.connectionsLock.Unlock()
+	if conn, ok := ep.connections[client]; ok {
+		if encodingConn, ok := conn.conn.(EncodingConn); ok {
+			return encodingConn
+		}
+	}
+	return nil
+}
+
+func toIntfSlice(strings []string) []any {
+	slice := make([]any, len(strings))
+	for i, s := range strings {
+		slice[i] = s
+	}
+	return slice
+}
+
+func toInt(value any) (int, bool) {
+	switch v := value.(type) {
+	case int64:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,69 @@
+package network
+
+import (
+	"log"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+)
+
+// GetClientIp returns the IP address of the client that sent the HTTP request.
+// The X-Real-Ip and X-Forwarded-For headers set by reverse proxies are only respected
+// if the request was received from a trusted proxy (see config.TrustedProxies), otherwise they could be spoofed by any client.
+func GetClientIp(request *http.Request) string {
+	if !isTrustedProxy(request.RemoteAddr) {
+		return request.RemoteAddr
+	}
+	if clientIp := strings.TrimSpace(request.Header.Get("X-Real-Ip")); clientIp != "" {
+		return clientIp
+	}
+	// every proxy appends the address it received the request from,
+	// so the rightmost address that is not a trusted proxy is the client
+	forwardedFor := strings.Split(strings.Join(request.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwardedFor[i])
+		if addr == "" {
+			continue
+		}
+		if i == 0 || !isTrustedProxy(addr) {
+			return addr
+		}
+	}
+	return request.RemoteAddr
+}
+
+// parses config.TrustedProxies once
+var trustedProxies = sync.OnceValue(func() []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(config.TrustedProxies))
+	for _, proxy := range config.TrustedProxies {
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			log.Printf("Invalid trusted proxy %q (IP address or CIDR required), ignoring it\n", proxy)
+			continue
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes
+})
+
+// isTrustedProxy reports whether the address ("ip" or "ip:port") belongs to a trusted proxy
+func isTrustedProxy(addr string) bool {
+	ip, err := netip.ParseAddr(stripPort(addr))
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range trustedProxies() {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package network
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+)
+
+func TestGetClientIp(t *testing.T) {
+	tests := []struct {
+		remoteAddr   string
+		realIp       string
+		forwardedFor string
+		expected     string
+	}{
+		{"203.0.113.7:1234", "", "", "203.0.113.7:1234"},
+		{"203.0.113.7:1234", "1.2.3.4", "", "203.0.113.7:1234"},           // untrusted peer cannot spoof
+		{"203.0.113.7:1234", "", "1.2.3.4", "203.0.113.7:1234"},           // untrusted peer cannot spoof
+		{"127.0.0.1:1234", "198.51.100.1", "", "198.51.100.1"},            // trusted proxy
+		{"172.17.0.2:1234", "", "1.2.3.4", "172.17.0.2:1234"},             // private networks are not trusted by default
+		{"127.0.0.1:1234", "", "1.2.3.4, 198.51.100.1", "198.51.100.1"},   // spoofed first entry is ignored
+		{"127.0.0.1:1234", "", "198.51.100.1, 127.0.0.2", "198.51.100.1"}, // chain of trusted proxies
+		{"[::1]:1234", "", "10.0.0.5", "10.0.0.5"},                        // only proxies in the header
+		{"[::ffff:127.0.0.1]:1234", "198.51.100.1", "", "198.51.100.1"},   // IPv4-mapped loopback
+		{"[::ffff:203.0.113.7]:1234", "1.2.3.4", "", "[::ffff:203.0.113.7]:1234"},
+		{"127.0.0.1:1234", " 198.51.100.1 ", "1.2.3.4", "198.51.100.1"}, // X-Real-Ip takes precedence
+		{"127.0.0.1:1234", "", "127.0.0.3, 127.0.0.2", "127.0.0.3"},     // only trusted proxies: the first one is the client
+		{"127.0.0.1:1234", "", "198.51.100.1, , ", "198.51.100.1"},      // empty entries are skipped
+		{"127.0.0.1:1234", "", " , ", "127.0.0.1:1234"},
+		{"127.0.0.1:1234", "", "not-an-ip, 127.0.0.2", "not-an-ip"},
+		{"127.0.0.1:1234", "", "127.0.0.2, not-an-ip", "not-an-ip"}, // garbage is never a trusted proxy
+		{"127.0.0.1", "198.51.100.1", "", "198.51.100.1"},           // remote address without port
+		{"", "198.51.100.1", "", ""},
+		{"localhost:1234", "198.51.100.1", "", "localhost:1234"}, // host names are not resolved
+		{"[::2]:1234", "198.51.100.1", "", "[::2]:1234"},
+	}
+	for _, test := range tests {
+		request := &http.Request{RemoteAddr: test.remoteAddr, Header: http.Header{}}
+		if test.realIp != "" {
+			request.Header.Set("X-Real-Ip", test.realIp)
+		}
+		if test.forwardedFor != "" {
+			request.Header.Set("X-Forwarded-For", test.forwardedFor)
+		}
+		if clientIp := GetClientIp(request); clientIp != test.expected {
+			t.Errorf("%+v: got %s", test, clientIp)
+		}
+	}
+
+	// the entries of multiple headers are combined in order
+	request := &http.Request{RemoteAddr: "127.0.0.1:1234", Header: http.Header{}}
+	request.Header.Add("X-Forwarded-For", "1.2.3.4, 198.51.100.1")
+	request.Header.Add("X-Forwarded-For", "203.0.113.7")
+	if clientIp := GetClientIp(request); clientIp != "203.0.113.7" {
+		t.Errorf("multiple X-Forwarded-For headers: got %s", clientIp)
+	}
+}
+
+func TestConnectionLimits(t *testing.T) {
+	maxConnections, maxConnectionsPerIp := config.MaxConnections, config.MaxConnectionsPerIp
+	config.MaxConnections, config.MaxConnectionsPerIp = 3, 2
+	defer func() { config.MaxConnections, config.MaxConnectionsPerIp = maxConnections, maxConnectionsPerIp }()
+
+	if err := AcquireConnection("192.0.2.1:1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := AcquireConnection("192.0.2.1:2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := AcquireConnection("192.0.2.1:3"); err != ErrTooManyConnectionsFromIp {
+		t.Fatalf("expected ErrTooManyConnectionsFromIp, got %v", err)
+	}
+	if err := AcquireConnection("192.0.2.2:1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := AcquireConnection("192.0.2.3:1"); err != ErrTooManyConnections {
+		t.Fatalf("expected ErrTooManyConnections, got %v", err)
+	}
+	ReleaseConnection("192.0.2.1:1")
+	if err := AcquireConnection("192.0.2.1:3"); err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{"192.0.2.1:2", "192.0.2.1:3", "192.0.2.2:1"} {
+		ReleaseConnection(addr)
+	}
+	if connectionCount.total != 0 || len(connectionCount.perIp) != 0 {
+		t.Fatalf("connections not released: %d %v", connectionCount.total, connectionCount.perIp)
+	}
+
+	// IPv6 addresses and addresses without port are counted per IP address as well
+	if err := AcquireConnection("[2001:db8::1]:1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := AcquireConnection("2001:db8::1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := AcquireConnection("[2001:db8::1]:2"); err != ErrTooManyConnectionsFromIp {
+		t.Fatalf("expected ErrTooManyConnectionsFromIp, got %v", err)
+	}
+	if err := AcquireConnection("[2001:db8::2]:1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{"2001:db8::1", "[2001:db8::1]:1", "[2001:db8::2]:1"} {
+		ReleaseConnection(addr)
+	}
+	if connectionCount.total != 0 || len(connectionCount.perIp) != 0 {
+		t.Fatalf("connections not released: %d %v", connectionCount.total, connectionCount.perIp)
+	}
+
+	// no limits
+	config.MaxConnections, config.MaxConnectionsPerIp = 0, 0
+	for range 5 {
+		if err := AcquireConnection("192.0.2.1:1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 5 {
+		ReleaseConnection("192.0.2.1:1")
+	}
+	if connectionCount.total != 0 || len(connectionCount.perIp) != 0 {
+		t.Fatalf("connections not released: %d %v", connectionCount.total, connectionCount.perIp)
+	}
+}
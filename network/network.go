@@ -1,8 +1,12 @@
 package network
 
 import (
+	"sync"
+
 	"github.com/ProjectLighthouseCAU/beacon/auth"
 	"github.com/ProjectLighthouseCAU/beacon/handler"
+	"github.com/ProjectLighthouseCAU/beacon/ratelimit"
+	"github.com/ProjectLighthouseCAU/beacon/types"
 )
 
 type EndpointType uint16 // Enum
@@ -11,18 +15,61 @@ const (
 	TCP
 	UDP
 	UNIX_DOMAIN
+	HTTP
 	// ...
 )
 
 // BaseEndpoint contains fields that are shared between all Endpoint implementations
+// and implements the transport-agnostic request pipeline (see pipeline.go)
 type BaseEndpoint struct {
 	Type    EndpointType
 	Handler *handler.Handler
 	Auth    auth.Auth
+	// limits the authorized requests before they are passed to the handler (nil: no limits)
+	RateLimiter *ratelimit.Limiter
+
+	SendStats SendQueueStats // outcome of sending responses to the clients of this endpoint
+
+	connections     map[*types.Client]*connection
+	connectionsLock sync.Mutex
 }
 
-// Endpoint is the interface which a specific endpoint has to implement
+// connection of a client with its send queue
+type connection struct {
+	conn  Conn
+	queue *sendQueue
+}
+
+// Endpoint is the interface which a specific endpoint has to implement.
+// An endpoint accepts connections and passes each of them as a Conn to BaseEndpoint.Serve.
 type Endpoint interface {
-	// TODO: unified interface between websocket, tcp, unix domain, etc.
+	// Close stops accepting new connections and disconnects all connected clients
 	Close()
 }
+
+// Conn is the interface which the connection of a specific transport has to implement.
+// It only provides the primitives for reading, writing and closing,
+// everything else is handled by the request pipeline.
+type Conn interface {
+	// Read blocks until the next MessagePack encoded request is received
+	Read() ([]byte, error)
+	// Write sends a MessagePack encoded response (never called concurrently)
+	Write(data []byte) error
+	// Close closes the connection (Write must fail afterwards, Read at the latest after a transport specific timeout).
+	// Transports that support it tell the client the code and reason for closing the connection.
+	Close(code CloseCode, reason string) error
+}
+
+// CertificateConn is implemented by connections whose client can be authenticated with a TLS client certificate
+type CertificateConn interface {
+	Conn
+	// CertificateUser returns the username of the verified client certificate ("" if none)
+	CertificateUser() string
+}
+
+// EncodingConn is implemented by connections that can switch between MessagePack and JSON (see the JSON feature of HELLO)
+type EncodingConn interface {
+	Conn
+	// SetJSON switches the encoding of the following requests and responses to JSON (or back to MessagePack if json is false)
+	SetJSON(json bool)
+}
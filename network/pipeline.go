@@ -0,0 +1,247 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/auth"
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+// maximum time to wait for the queued responses to be written when a client is disconnected
+const sendQueueFlushTimeout = time.Second
+
+var (
+	// returned by Conn.Read if the transport received data that cannot be a request (e.g. a websocket text message)
+	ErrUnsupportedData = errors.New("unsupported data")
+	// returned by Conn.Read if a request exceeds the configured size limit
+	ErrReadLimitExceeded = errors.New("request exceeds read limit")
+)
+
+// Serve creates a new Client for the connection and runs the request pipeline until the connection is closed (blocking call).
+// Every received request is decoded and queued, the queued requests are authorized and passed to the handler
+// one after another in a separate goroutine, so that CANCEL requests can be received while a request is processed.
+func (ep *BaseEndpoint) Serve(conn Conn, clientIp string) {
+	queue := newSendQueue(conn, &ep.SendStats)
+	client := types.NewClient(clientIp, queue.send)
+	if certConn, ok := conn.(CertificateConn); ok {
+		client.SetCertificateUser(certConn.CertificateUser())
+	}
+	client.SetAuthorizer(ep.Authorizer(client))
+	if config.SessionGracePeriod > 0 {
+		client.SetSession(newSessionId())
+	}
+	queue.onOverflow = func() {
+		ep.Disconnect(client, ClosePolicyViolation, ErrSlowConsumer.Error())
+	}
+	ep.connectionsLock.Lock()
+	if ep.connections == nil {
+		ep.connections = make(map[*types.Client]*connection)
+	}
+	ep.connections[client] = &connection{conn: conn, queue: queue}
+	ep.connectionsLock.Unlock()
+
+	closeCode, closeReason := CloseNormalClosure, ""
+	defer func() {
+		ep.Disconnect(client, closeCode, closeReason)
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Error while handling connection: ", r)
+			log.Println("Closing...")
+			closeCode, closeReason = CloseInternalServerErr, ""
+		}
+	}()
+
+	timeouts := ep.startConnectionTimeouts(client)
+	defer timeouts.stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	inflight := newInflightRequests()
+	requests := make(chan *inflightRequest, config.RequestQueueSize)
+	processed := make(chan struct{})
+	go func() {
+		defer close(processed)
+		ep.processRequests(client, requests, inflight, timeouts)
+	}()
+	defer func() {
+		cancel() // the queued and running requests are not answered anymore
+		close(requests)
+		<-processed
+	}()
+
+	for {
+		payload, err := conn.Read()
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrUnsupportedData):
+				response := types.NewResponse().Reid([]byte{0}).Rnum(http.StatusBadRequest).Warning(err.Error()).Build()
+				client.Send(response)
+				closeCode, closeReason = CloseUnsupportedData, ErrUnsupportedData.Error()
+			case errors.Is(err, ErrReadLimitExceeded):
+				response := types.NewResponse().Reid([]byte{0}).Rnum(http.StatusRequestEntityTooLarge).Warning(err.Error()).Build()
+				client.Send(response)
+				closeCode, closeReason = CloseMessageTooBig, ErrReadLimitExceeded.Error()
+			}
+			return
+		}
+		timeouts.requestReceived()
+		request, err := decodeRequest(client, payload)
+		if err != nil {
+			closeCode, closeReason = CloseInvalidFramePayloadData, "invalid request"
+			return
+		}
+		if request.VERB == "CANCEL" { // must not wait for the queued requests
+			ep.handleCancel(client, request, inflight)
+			continue
+		}
+		queued, err := inflight.add(ctx, request)
+		if err != nil {
+			if !client.NoResponse(request) {
+				client.Send(types.NewResponse().Reid(request.REID).Rnum(http.StatusBadRequest).Warning(err.Error()).Build())
+			}
+			continue
+		}
+		requests <- queued // blocks while the queue is full
+	}
+}
+
+// processRequests passes the queued requests of a client to HandleRequest in the order they were received
+func (ep *BaseEndpoint) processRequests(client *types.Client, requests <-chan *inflightRequest, inflight *inflightRequests, timeouts *connectionTimeouts) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Error while handling request: ", r)
+			log.Println("Closing...")
+			ep.Disconnect(client, CloseInternalServerErr, "")
+			for queued := range requests { // do not block the reading goroutine until it notices the closed connection
+				inflight.done(queued)
+			}
+		}
+	}()
+	for queued := range requests {
+		if ep.HandleRequest(queued.ctx, client, queued.request) {
+			timeouts.authorized()
+		}
+		inflight.done(queued)
+	}
+}
+
+// decodeRequest decodes a single request.
+// If the request cannot be decoded, an error response is sent and the error is returned
+// (the connection should then be closed since the client does not speak the protocol correctly).
+func decodeRequest(client *types.Client, payload []byte) (*types.Request, error) {
+	request := &types.Request{}
+	_, err := request.UnmarshalMsg(payload)
+	if err != nil {
+		response := types.NewResponse().Reid(request.REID).Rnum(http.StatusBadRequest).Warning("Could not deserialize request. Please make sure that you are using the Lighthouse-Protocol correctly").Build()
+		client.Send(response)
+		return nil, err
+	}
+	return request, nil
+}
+
+// HandleRequest checks authentication, authorization and rate limits of a request and passes it to the handler
+// (HELLO requests are answered without authorization, see handleHello, batch requests are split into their items, see handleBatch,
+// and requests with META SESSION resume a session, see resumeSession).
+// Requests whose context already ended are not processed: an exceeded deadline is answered with 408, canceled requests are not answered.
+// Returns whether the request was authorized.
+func (ep *BaseEndpoint) HandleRequest(ctx context.Context, client *types.Client, request *types.Request) bool {
+	if err := ctx.Err(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && !client.NoResponse(request) {
+			client.Send(types.NewResponse().Reid(request.REID).Rnum(http.StatusRequestTimeout).Warning("deadline exceeded before the request was processed").Build())
+		}
+		return false
+	}
+	switch request.VERB {
+	case "BATCH":
+		return ep.handleBatch(ctx, client, request)
+	case "HELLO":
+		return ep.handleHello(client, request)
+	}
+	if response, authorized := ep.authorize(client, request); response != nil {
+		if !client.NoResponse(request) {
+			client.Send(response)
+		}
+		return authorized
+	}
+	if id, ok := request.META["SESSION"].(string); ok && id != "" && id != client.Session() {
+		ep.resumeSession(ctx, client, request, id)
+		return true
+	}
+	ep.Handler.HandleRequest(ctx, client, request)
+	return true
+}
+
+// Authorizer returns the function that checks requests on behalf of the client with the auth of the endpoint (see types.Client.SetAuthorizer)
+func (ep *BaseEndpoint) Authorizer(client *types.Client) func(*types.Request) bool {
+	return func(request *types.Request) bool {
+		ok, _ := ep.Auth.IsAuthorized(client, request)
+		return ok
+	}
+}
+
+// authorize checks authentication, authorization (on the source path as well, see auth.SourceRequest) and rate limits of a request.
+// Returns the error response if the request must not be passed to the handler (otherwise nil)
+// and whether the request was authorized (rate limited requests are authorized).
+func (ep *BaseEndpoint) authorize(client *types.Client, request *types.Request) (response *types.Response, authorized bool) {
+	if ok, code := ep.Auth.IsAuthorized(client, request); !ok {
+		return types.NewResponse().Reid(request.REID).Rnum(code).Build(), false
+	}
+	if source, ok := auth.SourceRequest(request); ok {
+		if ok, code := ep.Auth.IsAuthorized(client, source); !ok {
+			return types.NewResponse().Reid(request.REID).Rnum(code).Warning("not authorized on the source path").Build(), false
+		}
+	}
+	if ok, retryAfter := ep.RateLimiter.Allow(client, request); !ok {
+		return types.NewResponse().Reid(request.REID).Rnum(http.StatusTooManyRequests).
+			Meta("RETRY_AFTER", retryAfter.Milliseconds()).
+			Warning(fmt.Sprintf("Rate limit exceeded, retry after %s", retryAfter.Round(time.Millisecond))).Build(), true
+	}
+	return nil, true
+}
+
+// Disconnect stops all streams of a client and closes its connection with the given code and reason
+// (no-op if already disconnected)
+func (ep *BaseEndpoint) Disconnect(client *types.Client, code CloseCode, reason string) {
+	ep.connectionsLock.Lock()
+	conn, ok := ep.connections[client]
+	delete(ep.connections, client)
+	ep.connectionsLock.Unlock()
+	if !ok {
+		return
+	}
+	saveSession(client) // before the streams are stopped
+	client.Disconnect()
+	ep.RateLimiter.Forget(client)
+	// send the remaining responses (e.g. the reason for closing the connection) before closing
+	if dropped := conn.queue.close(sendQueueFlushTimeout); dropped > 0 {
+		log.Printf("Dropped %d responses to slow client %s\n", dropped, client.Ip())
+	}
+	conn.conn.Close(code, reason)
+	if reason != "" {
+		log.Printf("Client disconnected: %s (%s)\n", client.Ip(), reason)
+	} else {
+		log.Println("Client disconnected: ", client.Ip())
+	}
+}
+
+// DisconnectAll disconnects all clients of this endpoint
+func (ep *BaseEndpoint) DisconnectAll(code CloseCode, reason string) {
+	ep.connectionsLock.Lock()
+	clients := make([]*types.Client, 0, len(ep.connections))
+	for client := range ep.connections {
+		clients = append(clients, client)
+	}
+	ep.connectionsLock.Unlock()
+	for _, client := range clients {
+		log.Println("Disconnecting ", client.Ip())
+		ep.Disconnect(client, code, reason)
+	}
+	log.Println("Send queue statistics:", &ep.SendStats)
+}
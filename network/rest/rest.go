@@ -0,0 +1,309 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/auth"
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/handler"
+	"github.com/ProjectLighthouseCAU/beacon/network"
+	"github.com/ProjectLighthouseCAU/beacon/ratelimit"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// Endpoint defines an HTTP endpoint that maps REST calls onto the verbs of the Lighthouse-Protocol:
+//
+//	GET    /r/<path>                -> GET (or LIST if the path is a directory)
+//	PUT    /r/<path>                -> PUT
+//	PATCH  /r/<path>                -> PATCH
+//	POST   /r/<path>                -> POST
+//	DELETE /r/<path>                -> DELETE
+//	POST   /r/<path>?link=<src>     -> LINK (destination: path, source: src)
+//	POST   /r/<path>?unlink=<src>   -> UNLINK
+//
+// Credentials are taken from the X-Lighthouse-User and X-Lighthouse-Token headers or from HTTP basic auth.
+// Request bodies are accepted as MessagePack (default) or JSON depending on the Content-Type header.
+// Response payloads are sent as JSON unless the Accept header asks for MessagePack.
+// The HTTP status code is the RNUM of the response, warnings are sent as X-Lighthouse-Warning headers.
+// The version of a resource is sent as ETag header and can be passed to PUT, PATCH and POST as If-Match header.
+//
+// Additionally, GET /stream/<path> streams a resource as Server-Sent Events (see sse.go).
+type Endpoint struct {
+	network.BaseEndpoint
+	httpServer *http.Server
+	closed     chan struct{} // closed when the endpoint is closed to end long-lived event streams
+}
+
+var _ network.Endpoint = (*Endpoint)(nil)
+
+const (
+	resourceRoute = "/r/"
+	streamRoute   = "/stream/"
+
+	contentTypeJSON    = "application/json"
+	contentTypeMsgpack = "application/msgpack"
+)
+
+// CreateEndpoint starts the HTTP server of the REST endpoint (non-blocking)
+func CreateEndpoint(host string, port int, auth auth.Auth, limiter *ratelimit.Limiter, handler *handler.Handler) *Endpoint {
+	ep := &Endpoint{
+		BaseEndpoint: network.BaseEndpoint{
+			Type:        network.HTTP,
+			Auth:        auth,
+			RateLimiter: limiter,
+			Handler:     handler,
+		},
+		httpServer: &http.Server{Addr: fmt.Sprintf("%s:%d", host, port)},
+		closed:     make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(resourceRoute, ep.handleResource)
+	mux.HandleFunc(streamRoute, ep.handleStream)
+	ep.httpServer.Handler = mux
+	go func() {
+		if err := network.ListenAndServe(ep.httpServer); err != http.ErrServerClosed {
+			log.Panicf("ListenAndServe returned: %v", err)
+		}
+	}()
+
+	log.Printf("REST Endpoint created: %s://%s:%d%s", network.HttpScheme(false), host, port, resourceRoute)
+
+	return ep
+}
+
+// Close closes the REST Endpoint
+func (ep *Endpoint) Close() {
+	log.Println("Closing REST endpoint")
+	close(ep.closed)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	err := ep.httpServer.Shutdown(ctx)
+	if err != nil {
+		log.Println("Failed to gracefully shutdown REST endpoint:", err)
+		ep.httpServer.Close()
+		log.Println("Forcefully closed REST endpoint")
+	}
+	log.Println("REST endpoint closed")
+}
+
+// handleResource translates an HTTP request into a Lighthouse request, passes it through the request pipeline
+// and writes the response back
+func (ep *Endpoint) handleResource(w http.ResponseWriter, r *http.Request) {
+	request, status, err := ep.translateRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// a client only lives for the duration of a single HTTP request
+	responses := make(chan *types.Response, 1)
+	client := types.NewClient(network.GetClientIp(r), func(response *types.Response) error {
+		select {
+		case responses <- response:
+			return nil
+		default:
+			return errors.New("response already sent")
+		}
+	})
+	client.SetCertificateUser(network.CertificateUser(r))
+	client.SetAuthorizer(ep.Authorizer(client))
+	defer client.Disconnect()
+	defer ep.RateLimiter.Forget(client)
+
+	// the request is aborted when the HTTP client disconnects or its deadline is exceeded
+	ctx, cancel, err := network.RequestContext(r.Context(), request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer cancel()
+	ep.HandleRequest(ctx, client, request)
+
+	select {
+	case response := <-responses:
+		writeResponse(w, r, response)
+	default:
+		http.Error(w, "no response", http.StatusInternalServerError)
+	}
+}
+
+// translateRequest maps method, path, query, headers and body of an HTTP request onto a Lighthouse request.
+// If the HTTP request cannot be translated, a suitable status code and error are returned.
+func (ep *Endpoint) translateRequest(r *http.Request) (*types.Request, int, error) {
+	request := &types.Request{
+		REID: msgp.AppendNil(nil),
+		AUTH: parseAuth(r),
+		PATH: parsePath(r.URL.Path, resourceRoute),
+		META: types.Meta{},
+	}
+
+	query := r.URL.Query()
+	if query.Has("deadline") { // milliseconds
+		deadline, err := strconv.ParseInt(query.Get("deadline"), 10, 64)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid deadline: %w", err)
+		}
+		request.META["DEADLINE"] = deadline
+	}
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" { // version from the ETag of a previous response
+		version, err := strconv.ParseUint(strings.Trim(ifMatch, `"`), 10, 64)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid If-Match: %w", err)
+		}
+		request.META["IF_MATCH"] = version
+	}
+	switch r.Method {
+	case http.MethodGet:
+		request.VERB = "GET"
+		if _, err := ep.Handler.GetDirectory().List(request.PATH); err == nil {
+			request.VERB = "LIST"
+			if query.Has("nonrecursive") {
+				request.META["NONRECURSIVE"] = true
+			}
+		}
+		return request, http.StatusOK, nil
+	case http.MethodDelete:
+		request.VERB = "DELETE"
+		return request, http.StatusOK, nil
+	case http.MethodPost:
+		// the verbs with a source path in the payload
+		for _, verb := range []string{"LINK", "UNLINK", "MOVE", "COPY"} {
+			if !query.Has(strings.ToLower(verb)) {
+				continue
+			}
+			request.VERB = verb
+			source := query.Get(strings.ToLower(verb))
+			payload, err := types.Path(parsePath(source, "")).MarshalMsg(nil)
+			if err != nil {
+				return nil, http.StatusBadRequest, err
+			}
+			request.PAYL = payload
+			return request, http.StatusOK, nil
+		}
+		request.VERB = "POST"
+	case http.MethodPut:
+		request.VERB = "PUT"
+	case http.MethodPatch:
+		request.VERB = "PATCH"
+	default:
+		return nil, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not supported", r.Method)
+	}
+
+	// PUT, PATCH and POST carry a payload
+	payload, status, err := readBody(r)
+	if err != nil {
+		return nil, status, err
+	}
+	request.PAYL = payload
+	return request, http.StatusOK, nil
+}
+
+// readBody reads the request body and converts it to MessagePack if necessary
+func readBody(r *http.Request) ([]byte, int, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, int64(config.RestReadLimit)+1))
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if len(body) > config.RestReadLimit {
+		return nil, http.StatusRequestEntityTooLarge, network.ErrReadLimitExceeded
+	}
+	if len(body) == 0 {
+		return nil, http.StatusOK, nil
+	}
+
+	mediaType := contentTypeMsgpack
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, http.StatusUnsupportedMediaType, err
+		}
+	}
+	switch mediaType {
+	case contentTypeJSON:
+		payload, err := types.JSONToMsgpack(body)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		return payload, http.StatusOK, nil
+	case contentTypeMsgpack, "application/x-msgpack", "application/vnd.msgpack":
+		if _, err := msgp.Skip(body); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		return body, http.StatusOK, nil
+	default:
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported Content-Type %s, use %s or %s", mediaType, contentTypeMsgpack, contentTypeJSON)
+	}
+}
+
+// writeResponse writes the status code, warnings and payload of a response
+func writeResponse(w http.ResponseWriter, r *http.Request, response *types.Response) {
+	for _, warning := range response.WARNINGS {
+		w.Header().Add("X-Lighthouse-Warning", warning)
+	}
+	if retryAfter, ok := response.META["RETRY_AFTER"].(int64); ok { // milliseconds -> seconds (rounded up)
+		w.Header().Set("Retry-After", strconv.FormatInt((retryAfter+999)/1000, 10))
+	}
+	if version, ok := response.META["VERSION"].(uint64); ok {
+		w.Header().Set("ETag", `"`+strconv.FormatUint(version, 10)+`"`)
+	}
+	if len(response.PAYL) == 0 {
+		if response.RNUM >= http.StatusBadRequest {
+			http.Error(w, strings.Join(append([]string{response.RESPONSE}, response.WARNINGS...), "\n"), response.RNUM)
+			return
+		}
+		w.WriteHeader(response.RNUM)
+		return
+	}
+	if strings.Contains(r.Header.Get("Accept"), "msgpack") {
+		w.Header().Set("Content-Type", contentTypeMsgpack)
+		w.WriteHeader(response.RNUM)
+		w.Write(response.PAYL)
+		return
+	}
+	payload, err := types.MsgpackToJSON(response.PAYL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(response.RNUM)
+	w.Write(payload)
+}
+
+// parseAuth reads the credentials from the X-Lighthouse-User and X-Lighthouse-Token headers or from HTTP basic auth
+func parseAuth(r *http.Request) map[string]string {
+	credentials := map[string]string{}
+	user, token, ok := r.BasicAuth()
+	if !ok {
+		user = r.Header.Get("X-Lighthouse-User")
+		token = r.Header.Get("X-Lighthouse-Token")
+	}
+	if user != "" {
+		credentials["USER"] = user
+	}
+	if token != "" {
+		credentials["TOKEN"] = token
+	}
+	return credentials
+}
+
+// parsePath converts a URL path (e.g. "/r/user/name/model") into a resource path by removing the route prefix
+func parsePath(urlPath string, route string) []string {
+	path := []string{}
+	for _, elem := range strings.Split(strings.TrimPrefix(urlPath, route), "/") {
+		if elem != "" {
+			path = append(path, elem)
+		}
+	}
+	return path
+}
@@ -0,0 +1,131 @@
+package rest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/network"
+	"github.com/ProjectLighthouseCAU/beacon/resource"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// handleStream streams a resource as Server-Sent Events (text/event-stream).
+// The current content of the resource is sent first, followed by every update.
+// By default, the content is sent as JSON. With the query parameter ?encoding=base64
+// (or if the content cannot be represented as JSON) the raw MessagePack is sent base64 encoded
+// in an event named "base64".
+// Since the browser EventSource API cannot set headers, credentials may also be passed
+// with the query parameters ?user=<username>&token=<token>.
+func (ep *Endpoint) handleStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, fmt.Sprintf("method %s is not supported", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	request := &types.Request{
+		REID: msgp.AppendNil(nil),
+		AUTH: parseAuth(r),
+		VERB: "STREAM",
+		PATH: parsePath(r.URL.Path, streamRoute),
+		META: types.Meta{},
+	}
+	if query.Has("user") {
+		request.AUTH["USER"] = query.Get("user")
+	}
+	if query.Has("token") {
+		request.AUTH["TOKEN"] = query.Get("token")
+	}
+
+	clientIp := network.GetClientIp(r)
+	if err := network.AcquireConnection(clientIp); err != nil {
+		network.RejectConnection(w, err)
+		return
+	}
+	defer network.ReleaseConnection(clientIp)
+
+	client := types.NewClient(clientIp, func(*types.Response) error { return nil })
+	client.SetCertificateUser(network.CertificateUser(r))
+	defer client.Disconnect()
+	defer ep.RateLimiter.Forget(client)
+
+	// same checks as the STREAM verb
+	if ok, code := ep.Auth.IsAuthorized(client, request); !ok {
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+	if ok, retryAfter := ep.RateLimiter.Allow(client, request); !ok {
+		w.Header().Set("Retry-After", strconv.FormatInt((retryAfter.Milliseconds()+999)/1000, 10))
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+	resrc, err := ep.Handler.GetDirectory().GetLeaf(request.PATH)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	stream := resrc.Stream()
+	defer resrc.StopStream(stream)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	forceBase64 := query.Get("encoding") == "base64"
+	if err := writeEvent(w, resrc.Get(), forceBase64); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(config.RestStreamKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done(): // client disconnected
+			return
+		case <-ep.closed:
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case content, ok := <-stream:
+			if !ok { // resource deleted
+				return
+			}
+			if err := writeEvent(w, content, forceBase64); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes the content of a resource as a single Server-Sent Event
+func writeEvent(w http.ResponseWriter, content resource.Content, forceBase64 bool) error {
+	if !forceBase64 {
+		data, err := types.MsgpackToJSON(content)
+		if err == nil {
+			_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+			return err
+		}
+		if config.VerboseLogging {
+			log.Println("[SSE] Could not convert content to JSON, sending base64 instead:", err)
+		}
+	}
+	_, err := fmt.Fprintf(w, "event: base64\ndata: %s\n\n", base64.StdEncoding.EncodeToString(content))
+	return err
+}
@@ -0,0 +1,203 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+var (
+	// returned by the send function of a client if its connection is closed
+	ErrConnectionClosed = errors.New("connection closed")
+	// returned by the send function of a client if its send queue is full and the slow consumer policy is "disconnect"
+	ErrSlowConsumer = errors.New("send queue full")
+)
+
+// SlowConsumerPolicy decides what happens if a response is sent to a client whose send queue is full
+type SlowConsumerPolicy int
+
+const (
+	// drop the oldest queued response with the same REID (i.e. an outdated update of the same stream),
+	// if there is none, the new response is dropped
+	DropOldest SlowConsumerPolicy = iota
+	// drop the new response
+	DropNewest
+	// disconnect the client
+	Disconnect
+)
+
+// ParseSlowConsumerPolicy parses the values of config.SendQueuePolicy (defaults to DropOldest)
+func ParseSlowConsumerPolicy(policy string) SlowConsumerPolicy {
+	switch policy {
+	case "drop_oldest":
+		return DropOldest
+	case "drop_newest":
+		return DropNewest
+	case "disconnect":
+		return Disconnect
+	default:
+		log.Printf("Unknown send queue policy %q, using \"drop_oldest\"\n", policy)
+		return DropOldest
+	}
+}
+
+// SendQueueStats counts the outcome of sending responses to the clients of an endpoint
+type SendQueueStats struct {
+	Sent                    atomic.Uint64 // responses written to a connection
+	DroppedOldest           atomic.Uint64 // outdated responses replaced by a newer one of the same stream
+	DroppedNewest           atomic.Uint64 // new responses dropped because the send queue was full
+	SlowConsumerDisconnects atomic.Uint64 // clients disconnected because their send queue was full
+}
+
+func (s *SendQueueStats) String() string {
+	return fmt.Sprintf("sent: %d, dropped oldest: %d, dropped newest: %d, slow consumer disconnects: %d",
+		s.Sent.Load(), s.DroppedOldest.Load(), s.DroppedNewest.Load(), s.SlowConsumerDisconnects.Load())
+}
+
+type queuedResponse struct {
+	reid string
+	data []byte
+}
+
+// sendQueue is a bounded outbound queue of a connection.
+// Responses are encoded by the sending goroutine and written to the connection by a dedicated writer goroutine,
+// so a slow client does not block its stream goroutines and request loop.
+type sendQueue struct {
+	conn   Conn
+	size   int
+	policy SlowConsumerPolicy
+	stats  *SendQueueStats // statistics of the endpoint
+	// called (once) if the queue overflows with the Disconnect policy
+	onOverflow func()
+
+	lock    sync.Mutex
+	queue   []queuedResponse
+	closed  bool
+	dropped uint64        // dropped responses of this connection
+	notify  chan struct{} // signals the writer that the queue is not empty or closed
+	done    chan struct{} // closed when the writer returns
+}
+
+func newSendQueue(conn Conn, stats *SendQueueStats) *sendQueue {
+	q := &sendQueue{
+		conn:   conn,
+		size:   max(config.SendQueueSize, 1),
+		policy: ParseSlowConsumerPolicy(config.SendQueuePolicy),
+		stats:  stats,
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go q.writeLoop()
+	return q
+}
+
+// send encodes the response and appends it to the queue (the send function of the client)
+func (q *sendQueue) send(response *types.Response) error {
+	data, err := response.MarshalMsg(nil)
+	if err != nil {
+		return err
+	}
+	q.lock.Lock()
+	if q.closed {
+		q.lock.Unlock()
+		return ErrConnectionClosed
+	}
+	item := queuedResponse{reid: string(response.REID), data: data}
+	if len(q.queue) >= q.size {
+		switch q.policy {
+		case DropOldest:
+			i := q.indexOf(item.reid)
+			if i < 0 {
+				q.dropped++
+				q.lock.Unlock()
+				q.stats.DroppedNewest.Add(1)
+				return nil
+			}
+			q.queue = append(q.queue[:i], q.queue[i+1:]...)
+			q.dropped++
+			q.stats.DroppedOldest.Add(1)
+		case DropNewest:
+			q.dropped++
+			q.lock.Unlock()
+			q.stats.DroppedNewest.Add(1)
+			return nil
+		case Disconnect:
+			q.closed = true
+			q.lock.Unlock()
+			q.stats.SlowConsumerDisconnects.Add(1)
+			if q.onOverflow != nil {
+				go q.onOverflow()
+			}
+			return ErrSlowConsumer
+		}
+	}
+	q.queue = append(q.queue, item)
+	q.lock.Unlock()
+	select {
+	case q.notify <- struct{}{}:
+	default: // the writer is already notified
+	}
+	return nil
+}
+
+// indexOf returns the index of the oldest queued response with the given REID or -1
+func (q *sendQueue) indexOf(reid string) int {
+	for i, item := range q.queue {
+		if item.reid == reid {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeLoop writes the queued responses to the connection until the queue is closed and flushed
+// or writing fails (in which case the connection is closed)
+func (q *sendQueue) writeLoop() {
+	defer close(q.done)
+	var batch []queuedResponse
+	for range q.notify {
+		q.lock.Lock()
+		batch, q.queue = q.queue, batch[:0]
+		closed := q.closed
+		q.lock.Unlock()
+		for _, item := range batch {
+			if err := q.conn.Write(item.data); err != nil {
+				q.lock.Lock()
+				q.closed = true
+				q.queue = nil
+				q.lock.Unlock()
+				q.conn.Close(CloseNormalClosure, "")
+				return
+			}
+			q.stats.Sent.Add(1)
+		}
+		clear(batch)
+		if closed { // nothing is added to a closed queue
+			return
+		}
+	}
+}
+
+// close stops accepting responses and waits (up to the given timeout) until the queued responses are written
+// (returns the number of dropped responses of this connection)
+func (q *sendQueue) close(timeout time.Duration) uint64 {
+	q.lock.Lock()
+	q.closed = true
+	dropped := q.dropped
+	q.lock.Unlock()
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	select {
+	case <-q.done:
+	case <-time.After(timeout):
+	}
+	return dropped
+}
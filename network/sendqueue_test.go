@@ -0,0 +1,122 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// blockingConn blocks every write until unblocked and records the written responses
+type blockingConn struct {
+	blocked chan struct{} // signaled when a write starts blocking
+	unblock chan struct{}
+	written chan []byte
+}
+
+func newBlockingConn() *blockingConn {
+	return &blockingConn{blocked: make(chan struct{}, 1), unblock: make(chan struct{}), written: make(chan []byte, 100)}
+}
+
+func (c *blockingConn) Read() ([]byte, error) { select {} }
+func (c *blockingConn) Write(data []byte) error {
+	select {
+	case c.blocked <- struct{}{}:
+	default:
+	}
+	<-c.unblock
+	c.written <- data
+	return nil
+}
+func (c *blockingConn) Close(code CloseCode, reason string) error { return nil }
+
+func newTestQueue(t *testing.T, policy string) (*sendQueue, *blockingConn, *SendQueueStats) {
+	size, oldPolicy := config.SendQueueSize, config.SendQueuePolicy
+	config.SendQueueSize, config.SendQueuePolicy = 2, policy
+	t.Cleanup(func() { config.SendQueueSize, config.SendQueuePolicy = size, oldPolicy })
+	conn := newBlockingConn()
+	stats := &SendQueueStats{}
+	q := newSendQueue(conn, stats)
+	// the first response is taken by the writer which then blocks
+	q.send(testResponse(0, 0))
+	select {
+	case <-conn.blocked:
+	case <-time.After(time.Second):
+		t.Fatal("writer did not take the first response")
+	}
+	return q, conn, stats
+}
+
+func testResponse(reid, payload int64) *types.Response {
+	return types.NewResponse().Reid(msgp.AppendInt64(nil, reid)).Rnum(200).Payload(msgp.AppendInt64(nil, payload)).Build()
+}
+
+func payloads(t *testing.T, conn *blockingConn, n int) []int64 {
+	close(conn.unblock)
+	var result []int64
+	for range n {
+		select {
+		case data := <-conn.written:
+			var response types.Response
+			if _, err := response.UnmarshalMsg(data); err != nil {
+				t.Fatal(err)
+			}
+			payload, _, _ := msgp.ReadInt64Bytes(response.PAYL)
+			result = append(result, payload)
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d responses, got %v", n, result)
+		}
+	}
+	return result
+}
+
+func TestSendQueueDropOldest(t *testing.T) {
+	q, conn, stats := newTestQueue(t, "drop_oldest")
+	q.send(testResponse(1, 1))
+	q.send(testResponse(2, 2))
+	q.send(testResponse(1, 3)) // replaces 1
+	q.send(testResponse(3, 4)) // no update of the same stream queued -> dropped
+	got := payloads(t, conn, 3)
+	if got[0] != 0 || got[1] != 2 || got[2] != 3 {
+		t.Fatalf("unexpected responses %v", got)
+	}
+	if stats.DroppedOldest.Load() != 1 || stats.DroppedNewest.Load() != 1 {
+		t.Fatalf("unexpected statistics %s", stats)
+	}
+}
+
+func TestSendQueueDropNewest(t *testing.T) {
+	q, conn, stats := newTestQueue(t, "drop_newest")
+	q.send(testResponse(1, 1))
+	q.send(testResponse(1, 2))
+	q.send(testResponse(1, 3))
+	got := payloads(t, conn, 3)
+	if got[0] != 0 || got[1] != 1 || got[2] != 2 {
+		t.Fatalf("unexpected responses %v", got)
+	}
+	if stats.DroppedNewest.Load() != 1 {
+		t.Fatalf("unexpected statistics %s", stats)
+	}
+}
+
+func TestSendQueueDisconnect(t *testing.T) {
+	q, conn, stats := newTestQueue(t, "disconnect")
+	overflow := make(chan struct{})
+	q.onOverflow = func() { close(overflow) }
+	q.send(testResponse(1, 1))
+	q.send(testResponse(1, 2))
+	if err := q.send(testResponse(1, 3)); err != ErrSlowConsumer {
+		t.Fatalf("expected ErrSlowConsumer, got %v", err)
+	}
+	<-overflow
+	if err := q.send(testResponse(1, 4)); err != ErrConnectionClosed {
+		t.Fatalf("expected ErrConnectionClosed, got %v", err)
+	}
+	payloads(t, conn, 3)
+	q.close(time.Second)
+	if stats.SlowConsumerDisconnects.Load() != 1 {
+		t.Fatalf("unexpected statistics %s", stats)
+	}
+}
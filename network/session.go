@@ -0,0 +1,111 @@
+package network
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/handler"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+// the sessions of disconnected clients that can be resumed within config.SessionGracePeriod (shared by all endpoints)
+var sessions = struct {
+	lock     sync.Mutex
+	sessions map[string]*session
+}{sessions: make(map[string]*session)}
+
+// session stores the streams of a disconnected client
+type session struct {
+	subscriptions []types.Subscription
+	expiry        *time.Timer
+}
+
+// newSessionId returns a random session ID (that cannot be guessed by other clients)
+func newSessionId() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		log.Panicln("Could not generate session ID:", err)
+	}
+	return hex.EncodeToString(id)
+}
+
+// saveSession keeps the streams of a disconnecting client so they can be restored within config.SessionGracePeriod
+func saveSession(client *types.Client) {
+	id := client.Session()
+	if id == "" || config.SessionGracePeriod <= 0 {
+		return
+	}
+	subscriptions := client.Subscriptions()
+	if len(subscriptions) == 0 {
+		return
+	}
+	sessions.lock.Lock()
+	defer sessions.lock.Unlock()
+	sessions.sessions[id] = &session{
+		subscriptions: subscriptions,
+		expiry: time.AfterFunc(config.SessionGracePeriod, func() {
+			sessions.lock.Lock()
+			delete(sessions.sessions, id)
+			sessions.lock.Unlock()
+		}),
+	}
+}
+
+// takeSession removes the session with the given ID and returns its streams (nil if the session does not exist or expired)
+func takeSession(id string) []types.Subscription {
+	sessions.lock.Lock()
+	defer sessions.lock.Unlock()
+	s, ok := sessions.sessions[id]
+	if !ok {
+		return nil
+	}
+	delete(sessions.sessions, id)
+	s.expiry.Stop()
+	return s.subscriptions
+}
+
+// resumeSession handles an authorized request with META SESSION of a reconnected client.
+// The client takes over the session ID and the streams of the session are restored
+// with the same REIDs and options and the AUTH of the request (every restored stream is authorized again
+// and the client immediately receives the current content of the resource).
+// Streams of resources that were moved are restored at their current path (see Handler.Locate).
+// The response to the request contains META RESUMED, which is false if the session does not exist (anymore)
+// (like other responses, it is not sent for NORESPONSE, canceled requests and successful writes with NOACK).
+func (ep *BaseEndpoint) resumeSession(ctx context.Context, client *types.Client, request *types.Request, id string) {
+	subscriptions := takeSession(id)
+	resumed := subscriptions != nil
+	if resumed {
+		client.SetSession(id)
+	}
+
+	response := ep.Handler.Process(ctx, client, request)
+	response.Meta("RESUMED", resumed)
+	if !client.NoResponse(request) && !errors.Is(ctx.Err(), context.Canceled) && !handler.SkipAck(client, request, response) {
+		client.Send(response)
+	}
+
+	for _, subscription := range subscriptions {
+		meta := types.Meta{}
+		for key, value := range subscription.META {
+			meta[key] = value
+		}
+		ep.HandleRequest(ctx, client, &types.Request{
+			REID: subscription.REID,
+			AUTH: request.AUTH,
+			VERB: "STREAM",
+			PATH: ep.Handler.Locate(ctx, subscription.PATH, subscription.Resource),
+			META: meta,
+		})
+	}
+	if resumed {
+		log.Printf("Client %s resumed session with %d streams\n", client.Ip(), len(subscriptions))
+	} else {
+		log.Printf("Client %s tried to resume unknown session\n", client.Ip())
+	}
+}
@@ -0,0 +1,113 @@
+package network
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/auth"
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/directory/tree"
+	"github.com/ProjectLighthouseCAU/beacon/handler"
+	"github.com/ProjectLighthouseCAU/beacon/resource"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+	"github.com/tinylib/msgp/msgp"
+)
+
+func TestSessionResume(t *testing.T) {
+	gracePeriod := config.SessionGracePeriod
+	config.SessionGracePeriod = time.Minute
+	t.Cleanup(func() { config.SessionGracePeriod = gracePeriod })
+
+	ep := &BaseEndpoint{Handler: handler.New(tree.NewTree[resource.Resource[resource.Content]]()), Auth: auth.AllowAll()}
+	connect := func(session string) (*types.Client, <-chan *types.Response) {
+		responses := make(chan *types.Response, 16)
+		client := types.NewClient("client", func(response *types.Response) error {
+			responses <- response
+			return nil
+		})
+		client.SetSession(session)
+		conn := &closeConn{closed: make(chan CloseCode, 1)}
+		ep.connectionsLock.Lock()
+		if ep.connections == nil {
+			ep.connections = make(map[*types.Client]*connection)
+		}
+		ep.connections[client] = &connection{conn: conn, queue: newSendQueue(conn, &ep.SendStats)}
+		ep.connectionsLock.Unlock()
+		return client, responses
+	}
+	receive := func(responses <-chan *types.Response) *types.Response {
+		select {
+		case response := <-responses:
+			return response
+		case <-time.After(time.Second):
+			t.Fatal("no response received")
+			return nil
+		}
+	}
+	request := func(reid int, verb string, path []string, meta types.Meta) *types.Request {
+		if meta == nil {
+			meta = types.Meta{}
+		}
+		return &types.Request{REID: msgp.AppendInt(nil, reid), VERB: verb, PATH: path, META: meta, PAYL: msgp.AppendInt(nil, reid)}
+	}
+	reid := func(response *types.Response) int {
+		reid, _, _ := msgp.ReadIntBytes(response.REID)
+		return reid
+	}
+
+	client, responses := connect("session")
+	ep.HandleRequest(context.Background(), client, request(1, "POST", []string{"a"}, nil))
+	receive(responses)
+	ep.HandleRequest(context.Background(), client, request(7, "STREAM", []string{"a"}, nil))
+	if response := receive(responses); response.RNUM != http.StatusOK {
+		t.Fatalf("stream failed with %d", response.RNUM)
+	}
+	ep.Disconnect(client, CloseNormalClosure, "")
+
+	// the reconnected client receives the response to its request and its streams with the original REIDs
+	client, responses = connect("new-session")
+	ep.HandleRequest(context.Background(), client, request(2, "GET", []string{"a"}, types.Meta{"SESSION": "session"}))
+	if response := receive(responses); reid(response) != 2 || response.META["RESUMED"] != true {
+		t.Fatalf("session not resumed: %d %v", reid(response), response.META)
+	}
+	if response := receive(responses); reid(response) != 7 || response.RNUM != http.StatusOK {
+		t.Fatalf("stream not restored: %d %d", reid(response), response.RNUM)
+	}
+	if client.Session() != "session" {
+		t.Fatalf("client did not take over the session, has %q", client.Session())
+	}
+	ep.HandleRequest(context.Background(), client, request(3, "PUT", []string{"a"}, nil))
+	for range 2 { // response to the PUT and the update of the stream
+		if response := receive(responses); reid(response) != 3 && reid(response) != 7 {
+			t.Fatalf("unexpected response %d", reid(response))
+		}
+	}
+
+	// a session can only be resumed once
+	other, otherResponses := connect("other-session")
+	ep.HandleRequest(context.Background(), other, request(2, "GET", []string{"a"}, types.Meta{"SESSION": "session"}))
+	if response := receive(otherResponses); response.META["RESUMED"] != false {
+		t.Fatalf("session resumed twice: %v", response.META)
+	}
+	if other.Session() != "other-session" {
+		t.Fatalf("client took over the session %q", other.Session())
+	}
+	ep.Disconnect(other, CloseNormalClosure, "")
+	ep.Disconnect(client, CloseNormalClosure, "")
+
+	// sessions expire after the grace period
+	config.SessionGracePeriod = 10 * time.Millisecond
+	client, responses = connect("expiring")
+	ep.HandleRequest(context.Background(), client, request(7, "STREAM", []string{"a"}, nil))
+	receive(responses)
+	ep.Disconnect(client, CloseNormalClosure, "")
+	time.Sleep(50 * time.Millisecond)
+	if takeSession("expiring") != nil {
+		t.Fatal("session did not expire")
+	}
+	if takeSession("session") == nil {
+		t.Fatal("session of the disconnected client not saved")
+	}
+}
@@ -0,0 +1,126 @@
+package socket
+
+import (
+	"errors"
+	"log"
+	"net"
+
+	"github.com/ProjectLighthouseCAU/beacon/auth"
+	"github.com/ProjectLighthouseCAU/beacon/handler"
+	"github.com/ProjectLighthouseCAU/beacon/network"
+	"github.com/ProjectLighthouseCAU/beacon/ratelimit"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// Endpoint defines a stream socket endpoint (e.g. TCP or UNIX domain socket)
+// that reads and writes back-to-back MessagePack objects
+type Endpoint struct {
+	network.BaseEndpoint
+	name      string // transport name used in log messages
+	listener  net.Listener
+	readLimit int
+}
+
+var _ network.Endpoint = (*Endpoint)(nil)
+
+// NewEndpoint starts accepting connections on the given listener (non-blocking)
+func NewEndpoint(endpointType network.EndpointType, name string, listener net.Listener, readLimit int, auth auth.Auth, limiter *ratelimit.Limiter, handler *handler.Handler) *Endpoint {
+	ep := &Endpoint{
+		BaseEndpoint: network.BaseEndpoint{
+			Type:        endpointType,
+			Auth:        auth,
+			RateLimiter: limiter,
+			Handler:     handler,
+		},
+		name:      name,
+		listener:  listener,
+		readLimit: readLimit,
+	}
+	go ep.acceptLoop()
+	return ep
+}
+
+// Close closes the socket Endpoint
+func (ep *Endpoint) Close() {
+	log.Printf("Closing %s endpoint\n", ep.name)
+	err := ep.listener.Close()
+	if err != nil {
+		log.Printf("Failed to close %s listener: %v\n", ep.name, err)
+	}
+	ep.DisconnectAll(network.CloseNormalClosure, "")
+	log.Printf("%s endpoint closed\n", ep.name)
+}
+
+func (ep *Endpoint) acceptLoop() {
+	for {
+		conn, err := ep.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Printf("Error while accepting %s connection: %v\n", ep.name, err)
+			continue
+		}
+		clientIp := conn.RemoteAddr().String()
+		if clientIp == "" || clientIp == "@" { // unnamed unix domain socket peer
+			clientIp = ep.listener.Addr().String()
+		}
+		log.Printf("Incoming %s Connection from: %s\n", ep.name, clientIp)
+		if err := network.AcquireConnection(clientIp); err != nil {
+			log.Printf("Rejected %s connection from %s: %v\n", ep.name, clientIp, err)
+			conn.Close()
+			continue
+		}
+		go func() {
+			defer network.ReleaseConnection(clientIp)
+			ep.Serve(&socketConn{
+				conn:      conn,
+				reader:    msgp.NewReader(conn),
+				readLimit: ep.readLimit,
+			}, clientIp)
+		}()
+	}
+}
+
+// socketConn implements network.Conn for stream sockets.
+// Since MessagePack objects are self-delimiting, no additional length header is needed.
+type socketConn struct {
+	conn      net.Conn
+	reader    *msgp.Reader
+	readLimit int
+}
+
+var _ network.Conn = (*socketConn)(nil)
+
+// Read reads the next MessagePack object from the stream without decoding it
+func (c *socketConn) Read() ([]byte, error) {
+	buf := limitedBuffer{limit: c.readLimit}
+	_, err := c.reader.CopyNext(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.data, nil
+}
+
+func (c *socketConn) Write(data []byte) error {
+	_, err := c.conn.Write(data)
+	return err
+}
+
+func (c *socketConn) Close(code network.CloseCode, reason string) error {
+	return c.conn.Close()
+}
+
+// limitedBuffer collects written bytes and fails once more than limit bytes were written
+type limitedBuffer struct {
+	data  []byte
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if len(b.data)+len(p) > b.limit {
+		return 0, network.ErrReadLimitExceeded
+	}
+	b.data = append(b.data, p...)
+	return len(p), nil
+}
@@ -0,0 +1,37 @@
+package tcp
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/auth"
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/handler"
+	"github.com/ProjectLighthouseCAU/beacon/network"
+	"github.com/ProjectLighthouseCAU/beacon/network/socket"
+	"github.com/ProjectLighthouseCAU/beacon/ratelimit"
+)
+
+// CreateEndpoint starts listening for TCP connections (retries until the address can be bound)
+func CreateEndpoint(host string, port int, auth auth.Auth, limiter *ratelimit.Limiter, handler *handler.Handler) *socket.Endpoint {
+	addr := fmt.Sprintf("%s:%d", host, port)
+	var listener net.Listener
+	var err error
+	for {
+		listener, err = net.Listen("tcp", addr)
+		if err == nil {
+			break
+		}
+		log.Println("Error while creating TCP endpoint: ", err)
+		log.Println("Retrying in 3 seconds...")
+		time.Sleep(3 * time.Second)
+	}
+
+	ep := socket.NewEndpoint(network.TCP, "TCP", listener, config.TcpReadLimit, auth, limiter, handler)
+
+	log.Printf("TCP Endpoint created: tcp://%s", addr)
+
+	return ep
+}
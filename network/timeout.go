@@ -0,0 +1,86 @@
+package network
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+// connectionTimeouts disconnects a client that stays idle for too long (no requests and no open streams)
+// or that does not send a single authorized request within a shorter deadline after connecting.
+// A timeout of zero disables the respective check.
+type connectionTimeouts struct {
+	lock         sync.Mutex
+	idle         *time.Timer
+	lastRequest  time.Time // the idle timer may already have fired when a request is received, so it checks this time
+	unauthorized *time.Timer
+}
+
+func (ep *BaseEndpoint) startConnectionTimeouts(client *types.Client) *connectionTimeouts {
+	t := &connectionTimeouts{lastRequest: time.Now()}
+	t.lock.Lock() // the timers must not fire before they are stored
+	defer t.lock.Unlock()
+	if config.ConnectionIdleTimeout > 0 {
+		var onIdle func()
+		onIdle = func() {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if t.idle == nil { // stopped
+				return
+			}
+			if remaining := config.ConnectionIdleTimeout - time.Since(t.lastRequest); remaining > 0 { // request received meanwhile
+				t.idle.Reset(remaining)
+				return
+			}
+			if client.HasStreams() { // not idle, only passively receiving updates
+				t.idle.Reset(config.ConnectionIdleTimeout)
+				return
+			}
+			go ep.Disconnect(client, CloseNormalClosure, "idle timeout")
+		}
+		t.idle = time.AfterFunc(config.ConnectionIdleTimeout, onIdle)
+	}
+	if config.ConnectionUnauthorizedTimeout > 0 {
+		t.unauthorized = time.AfterFunc(config.ConnectionUnauthorizedTimeout, func() {
+			reason := fmt.Sprintf("no authorized request within %s", config.ConnectionUnauthorizedTimeout)
+			ep.Disconnect(client, ClosePolicyViolation, reason)
+		})
+	}
+	return t
+}
+
+// requestReceived resets the idle timeout
+func (t *connectionTimeouts) requestReceived() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.lastRequest = time.Now()
+	if t.idle != nil {
+		t.idle.Reset(config.ConnectionIdleTimeout)
+	}
+}
+
+// authorized stops the unauthorized-connection timeout
+func (t *connectionTimeouts) authorized() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.unauthorized != nil {
+		t.unauthorized.Stop()
+		t.unauthorized = nil
+	}
+}
+
+func (t *connectionTimeouts) stop() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.idle != nil {
+		t.idle.Stop()
+		t.idle = nil
+	}
+	if t.unauthorized != nil {
+		t.unauthorized.Stop()
+		t.unauthorized = nil
+	}
+}
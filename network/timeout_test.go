@@ -0,0 +1,56 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+// closeConn records whether it was closed
+type closeConn struct {
+	closed chan CloseCode
+}
+
+func (c *closeConn) Read() ([]byte, error)   { select {} }
+func (c *closeConn) Write(data []byte) error { return nil }
+func (c *closeConn) Close(code CloseCode, reason string) error {
+	c.closed <- code
+	return nil
+}
+
+func TestIdleTimeoutRequestWhileFiring(t *testing.T) {
+	timeout, unauthorizedTimeout := config.ConnectionIdleTimeout, config.ConnectionUnauthorizedTimeout
+	config.ConnectionIdleTimeout, config.ConnectionUnauthorizedTimeout = 50*time.Millisecond, 0
+	t.Cleanup(func() {
+		config.ConnectionIdleTimeout, config.ConnectionUnauthorizedTimeout = timeout, unauthorizedTimeout
+	})
+
+	ep := &BaseEndpoint{}
+	conn := &closeConn{closed: make(chan CloseCode, 1)}
+	client := types.NewClient("client", func(*types.Response) error { return nil })
+	ep.connections = map[*types.Client]*connection{client: {conn: conn, queue: newSendQueue(conn, &ep.SendStats)}}
+	timeouts := ep.startConnectionTimeouts(client)
+	defer timeouts.stop()
+
+	// the idle timer fires while a request is received (i.e. while requestReceived holds the lock)
+	timeouts.lock.Lock()
+	time.Sleep(60 * time.Millisecond)
+	timeouts.lastRequest = time.Now()
+	timeouts.idle.Reset(config.ConnectionIdleTimeout)
+	timeouts.lock.Unlock()
+	select {
+	case <-conn.closed:
+		t.Fatal("client was disconnected although it just sent a request")
+	case <-time.After(30 * time.Millisecond):
+	}
+	select {
+	case code := <-conn.closed:
+		if code != CloseNormalClosure {
+			t.Fatalf("unexpected close code %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("idle client was not disconnected")
+	}
+}
@@ -0,0 +1,184 @@
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+)
+
+// TLSEnabled reports whether the HTTP endpoints serve TLS (a certificate and key file are configured)
+func TLSEnabled() bool {
+	return config.TlsCertFile != "" && config.TlsKeyFile != ""
+}
+
+// ListenAndServe starts the HTTP server of an endpoint with TLS if it is enabled (blocking call)
+func ListenAndServe(server *http.Server) error {
+	if !TLSEnabled() {
+		return server.ListenAndServe()
+	}
+	tlsConfig, err := TLSConfig()
+	if err != nil {
+		return err
+	}
+	server.TLSConfig = tlsConfig
+	return server.ListenAndServeTLS("", "")
+}
+
+// HttpScheme returns "http" or "https" (or "ws" and "wss" if websocket is true) depending on whether TLS is enabled
+func HttpScheme(websocket bool) string {
+	switch {
+	case websocket && TLSEnabled():
+		return "wss"
+	case websocket:
+		return "ws"
+	case TLSEnabled():
+		return "https"
+	default:
+		return "http"
+	}
+}
+
+// the certificate reloader is shared by all endpoints
+var certReloader = sync.OnceValues(func() (*certificateReloader, error) {
+	r := &certificateReloader{}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	go r.watch()
+	return r, nil
+})
+
+// TLSConfig returns the TLS config of the HTTP endpoints.
+// The certificate (and client CA) files are reloaded when they change or the process receives SIGHUP,
+// new connections use the new certificate while existing connections are kept.
+func TLSConfig() (*tls.Config, error) {
+	reloader, err := certReloader()
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return reloader.config(), nil
+		},
+	}, nil
+}
+
+// certificateReloader keeps the current certificate and client CAs
+type certificateReloader struct {
+	lock      sync.RWMutex
+	tlsConfig *tls.Config
+	modTimes  map[string]time.Time
+}
+
+func (r *certificateReloader) config() *tls.Config {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.tlsConfig
+}
+
+// reload loads the certificate, key and client CA files
+func (r *certificateReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(config.TlsCertFile, config.TlsKeyFile)
+	if err != nil {
+		return fmt.Errorf("could not load TLS certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if config.TlsClientCaFile != "" {
+		pem, err := os.ReadFile(config.TlsClientCaFile)
+		if err != nil {
+			return fmt.Errorf("could not load TLS client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("could not load TLS client CA: no certificates found")
+		}
+		tlsConfig.ClientCAs = pool
+		// clients without certificate are still allowed and authenticate with their token
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	r.lock.Lock()
+	r.tlsConfig = tlsConfig
+	r.modTimes = modTimes()
+	r.lock.Unlock()
+	return nil
+}
+
+// watch reloads the files when their modification time changes or SIGHUP is received
+func (r *certificateReloader) watch() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	ticker := time.NewTicker(config.TlsReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-hangup:
+			log.Println("Received SIGHUP, reloading TLS certificates")
+		case <-ticker.C:
+			r.lock.RLock()
+			changed := !maps.EqualFunc(r.modTimes, modTimes(), time.Time.Equal)
+			r.lock.RUnlock()
+			if !changed {
+				continue
+			}
+			log.Println("TLS certificate files changed, reloading them")
+		}
+		if err := r.reload(); err != nil {
+			log.Println("Failed to reload TLS certificates, keeping the old ones:", err)
+			continue
+		}
+		log.Println("Reloaded TLS certificates")
+	}
+}
+
+// modTimes returns the modification times of the certificate files
+func modTimes() map[string]time.Time {
+	times := make(map[string]time.Time)
+	for _, file := range []string{config.TlsCertFile, config.TlsKeyFile, config.TlsClientCaFile} {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil {
+			times[file] = info.ModTime()
+		}
+	}
+	return times
+}
+
+// maps certificate subjects to usernames (parsed once from config.TlsClientCertUsersJson)
+var certificateUsers = sync.OnceValue(func() map[string]string {
+	users := map[string]string{}
+	err := json.Unmarshal([]byte(config.TlsClientCertUsersJson), &users)
+	if err != nil {
+		log.Println("Could not parse TLS client certificate users:", err)
+	}
+	return users
+})
+
+// CertificateUser returns the username of the verified client certificate of an HTTP request
+// (by its subject, e.g. "CN=exporter,O=Lighthouse", or only its common name) or "" if there is none
+func CertificateUser(request *http.Request) string {
+	if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 || len(request.TLS.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	subject := request.TLS.VerifiedChains[0][0].Subject
+	if user, ok := certificateUsers()[subject.String()]; ok {
+		return user
+	}
+	return certificateUsers()[subject.CommonName]
+}
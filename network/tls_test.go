@@ -0,0 +1,138 @@
+package network
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/config"
+)
+
+// writeCertificate writes a self-signed certificate with the given subject and its key to cert.pem and key.pem in dir
+func writeCertificate(t *testing.T, dir string, subject pkix.Name) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err := os.WriteFile(filepath.Join(dir, "cert.pem"), certPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "key.pem"), keyPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestCertificateReload(t *testing.T) {
+	certFile, keyFile, caFile := config.TlsCertFile, config.TlsKeyFile, config.TlsClientCaFile
+	t.Cleanup(func() { config.TlsCertFile, config.TlsKeyFile, config.TlsClientCaFile = certFile, keyFile, caFile })
+	dir := t.TempDir()
+	config.TlsCertFile, config.TlsKeyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	config.TlsClientCaFile = ""
+
+	first := writeCertificate(t, dir, pkix.Name{CommonName: "first"})
+	r := &certificateReloader{}
+	if err := r.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if cert := r.config().Certificates[0]; !cert.Leaf.Equal(first) {
+		t.Fatal("first certificate not loaded")
+	}
+	if r.config().ClientAuth != tls.NoClientCert {
+		t.Fatal("client certificates requested without client CA")
+	}
+
+	second := writeCertificate(t, dir, pkix.Name{CommonName: "second"})
+	if err := r.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if cert := r.config().Certificates[0]; !cert.Leaf.Equal(second) {
+		t.Fatal("changed certificate not loaded")
+	}
+
+	// invalid files are not loaded, the previous certificate is kept
+	if err := os.WriteFile(config.TlsCertFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.reload(); err == nil {
+		t.Fatal("invalid certificate loaded")
+	}
+	if cert := r.config().Certificates[0]; !cert.Leaf.Equal(second) {
+		t.Fatal("previous certificate not kept")
+	}
+
+	// client certificates are verified if they are given
+	writeCertificate(t, dir, pkix.Name{CommonName: "server"})
+	config.TlsClientCaFile = config.TlsCertFile
+	if err := r.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if r.config().ClientAuth != tls.VerifyClientCertIfGiven || r.config().ClientCAs == nil {
+		t.Fatal("client CA not loaded")
+	}
+	if _, ok := r.modTimes[config.TlsClientCaFile]; !ok {
+		t.Fatal("client CA file not watched")
+	}
+}
+
+func TestCertificateUser(t *testing.T) {
+	// certificateUsers is parsed once, so the mapping must be configured before it is used first
+	usersJson := config.TlsClientCertUsersJson
+	t.Cleanup(func() { config.TlsClientCertUsersJson = usersJson })
+	config.TlsClientCertUsersJson = `{"CN=exporter,O=Lighthouse": "metrics", "display": "display-user"}`
+
+	request := func(subject pkix.Name, verified bool) *http.Request {
+		cert := &x509.Certificate{Subject: subject}
+		state := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		if verified {
+			state.VerifiedChains = [][]*x509.Certificate{{cert}}
+		}
+		return &http.Request{TLS: state}
+	}
+	tests := []struct {
+		request  *http.Request
+		expected string
+	}{
+		{request(pkix.Name{CommonName: "exporter", Organization: []string{"Lighthouse"}}, true), "metrics"},
+		{request(pkix.Name{CommonName: "exporter"}, true), ""}, // the subject must match
+		{request(pkix.Name{CommonName: "display", Organization: []string{"Other"}}, true), "display-user"},
+		{request(pkix.Name{CommonName: "display"}, false), ""}, // certificates that were not verified are ignored
+		{request(pkix.Name{CommonName: "unknown"}, true), ""},
+		{&http.Request{}, ""},
+	}
+	for _, test := range tests {
+		if user := CertificateUser(test.request); user != test.expected {
+			t.Errorf("expected certificate user %q, got %q", test.expected, user)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package udp
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/auth"
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/handler"
+	"github.com/ProjectLighthouseCAU/beacon/network"
+	"github.com/ProjectLighthouseCAU/beacon/ratelimit"
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+// Endpoint defines a UDP endpoint that accepts exactly one request per datagram.
+// Since UDP is connectionless, every remote address is treated as a peer with its own connection.
+// A peer stays connected (and keeps receiving stream updates) as long as it sends datagrams,
+// an empty datagram can be used as a keep-alive.
+// Like on other transports, a datagram that cannot be decoded disconnects the peer.
+// Since the source address of a datagram can be spoofed, an unknown address only becomes a peer
+// if its first datagram is an authorized request (see admit), otherwise it does not receive any response.
+type Endpoint struct {
+	network.BaseEndpoint
+	conn      net.PacketConn
+	peers     map[string]*peerConn
+	pending   map[string]*peerConn // unknown addresses whose first request is being authorized
+	peersLock sync.Mutex
+}
+
+var _ network.Endpoint = (*Endpoint)(nil)
+
+// maximum number of unknown addresses that are authorized at the same time (further unknown addresses are ignored meanwhile)
+const maxPendingPeers = 16
+
+var errPeerExpired = errors.New("UDP peer expired")
+
+// CreateEndpoint starts listening for UDP datagrams (retries until the address can be bound)
+func CreateEndpoint(host string, port int, auth auth.Auth, limiter *ratelimit.Limiter, handler *handler.Handler) *Endpoint {
+	addr := fmt.Sprintf("%s:%d", host, port)
+	var conn net.PacketConn
+	var err error
+	for {
+		conn, err = net.ListenPacket("udp", addr)
+		if err == nil {
+			break
+		}
+		log.Println("Error while creating UDP endpoint: ", err)
+		log.Println("Retrying in 3 seconds...")
+		time.Sleep(3 * time.Second)
+	}
+
+	ep := &Endpoint{
+		BaseEndpoint: network.BaseEndpoint{
+			Type:        network.UDP,
+			Auth:        auth,
+			RateLimiter: limiter,
+			Handler:     handler,
+		},
+		conn:    conn,
+		peers:   make(map[string]*peerConn),
+		pending: make(map[string]*peerConn),
+	}
+	go ep.readLoop()
+
+	log.Printf("UDP Endpoint created: udp://%s", addr)
+
+	return ep
+}
+
+// Close closes the UDP Endpoint
+func (ep *Endpoint) Close() {
+	log.Println("Closing UDP endpoint")
+	err := ep.conn.Close()
+	if err != nil {
+		log.Println("Failed to close UDP socket:", err)
+	}
+	ep.DisconnectAll(network.CloseNormalClosure, "")
+	log.Println("UDP endpoint closed")
+}
+
+// readLoop reads datagrams and dispatches them to the connection of the sending peer.
+// Unknown addresses become peers if their first datagram is an authorized request (see admit),
+// peers are served by the request pipeline until they expire.
+func (ep *Endpoint) readLoop() {
+	for {
+		buf := make([]byte, config.UdpReadLimit)
+		n, addr, err := ep.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Println("Error while reading UDP datagram:", err)
+			continue
+		}
+		ep.peersLock.Lock()
+		peer, ok := ep.peers[addr.String()]
+		if !ok {
+			peer, ok = ep.pending[addr.String()] // datagrams are queued until the peer is admitted
+		}
+		if !ok {
+			if n == 0 || len(ep.pending) >= maxPendingPeers || len(ep.peers) >= config.UdpMaxPeers {
+				ep.peersLock.Unlock()
+				continue
+			}
+			request := &types.Request{}
+			if _, err := request.UnmarshalMsg(buf[:n]); err != nil {
+				ep.peersLock.Unlock()
+				continue
+			}
+			peer = &peerConn{
+				endpoint: ep,
+				addr:     addr,
+				incoming: make(chan []byte, config.UdpPeerQueueSize),
+				closed:   make(chan struct{}),
+			}
+			ep.pending[addr.String()] = peer
+			go ep.admit(peer, request)
+		}
+		ep.peersLock.Unlock()
+		select {
+		case peer.incoming <- buf[:n]:
+		default:
+			if config.VerboseLogging {
+				log.Println("[Warning] UDP peer is too slow, dropped datagram from ", addr.String())
+			}
+		}
+	}
+}
+
+// admit registers an unknown address as peer and serves it if its first request is authorized by the auth
+// (even HELLO, so that unauthorized requests cannot be used to reflect responses to spoofed addresses)
+// and the connection limits and UDP_MAX_PEERS are not exceeded. Otherwise the address is ignored without a response.
+func (ep *Endpoint) admit(peer *peerConn, request *types.Request) {
+	addr := peer.addr.String()
+	client := types.NewClient(addr, func(*types.Response) error { return nil }) // only for authorizing the request
+	authorized, _ := ep.Auth.IsAuthorized(client, request)
+	client.Disconnect()
+
+	ep.peersLock.Lock()
+	delete(ep.pending, addr)
+	if !authorized || len(ep.peers) >= config.UdpMaxPeers {
+		ep.peersLock.Unlock()
+		if config.VerboseLogging {
+			log.Printf("Ignored UDP datagrams from %s (unauthorized or too many peers)\n", addr)
+		}
+		return
+	}
+	if err := network.AcquireConnection(addr); err != nil {
+		ep.peersLock.Unlock()
+		if config.VerboseLogging {
+			log.Printf("Rejected UDP peer %s: %v\n", addr, err)
+		}
+		return
+	}
+	ep.peers[addr] = peer
+	ep.peersLock.Unlock()
+	if config.VerboseLogging {
+		log.Println("New UDP peer: ", addr)
+	}
+	defer network.ReleaseConnection(addr)
+	ep.Serve(peer, addr)
+}
+
+// peerConn implements network.Conn for a single UDP peer
+type peerConn struct {
+	endpoint  *Endpoint
+	addr      net.Addr
+	incoming  chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+var _ network.Conn = (*peerConn)(nil)
+
+// Read returns the next datagram of this peer (empty keep-alive datagrams are skipped)
+// and fails if the peer did not send anything within config.UdpPeerTimeout
+func (c *peerConn) Read() ([]byte, error) {
+	for {
+		select {
+		case <-c.closed:
+			return nil, net.ErrClosed
+		case <-time.After(config.UdpPeerTimeout):
+			log.Println("UDP peer expired: ", c.addr.String())
+			return nil, errPeerExpired
+		case payload := <-c.incoming:
+			if len(payload) == 0 { // keep-alive
+				continue
+			}
+			return payload, nil
+		}
+	}
+}
+
+func (c *peerConn) Write(data []byte) error {
+	select {
+	case <-c.closed:
+		return net.ErrClosed
+	default:
+	}
+	_, err := c.endpoint.conn.WriteTo(data, c.addr)
+	return err
+}
+
+func (c *peerConn) Close(code network.CloseCode, reason string) error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.endpoint.peersLock.Lock()
+		if c.endpoint.peers[c.addr.String()] == c {
+			delete(c.endpoint.peers, c.addr.String())
+		}
+		c.endpoint.peersLock.Unlock()
+	})
+	return nil
+}
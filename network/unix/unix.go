@@ -0,0 +1,106 @@
+package unix
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/auth"
+	"github.com/ProjectLighthouseCAU/beacon/config"
+	"github.com/ProjectLighthouseCAU/beacon/handler"
+	"github.com/ProjectLighthouseCAU/beacon/network"
+	"github.com/ProjectLighthouseCAU/beacon/network/socket"
+	"github.com/ProjectLighthouseCAU/beacon/ratelimit"
+)
+
+// CreateEndpoint starts listening for connections on a UNIX domain socket at the given path
+// and restricts access to the socket using the given file mode (retries until the socket can be created).
+// The socket is created in a private directory and only moved to the path after its file mode was set,
+// so it is never accessible with broader permissions. Exits if the file mode cannot be set.
+func CreateEndpoint(path string, mode os.FileMode, auth auth.Auth, limiter *ratelimit.Limiter, handler *handler.Handler) *socket.Endpoint {
+	removeStaleSocket(path)
+
+	var listener net.Listener
+	var err error
+	for {
+		listener, err = listen(path, mode)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, errChmod) {
+			log.Fatalln(err)
+		}
+		log.Println("Error while creating UNIX domain socket endpoint: ", err)
+		log.Println("Retrying in 3 seconds...")
+		time.Sleep(3 * time.Second)
+	}
+
+	ep := socket.NewEndpoint(network.UNIX_DOMAIN, "UNIX domain socket", listener, config.UnixSocketReadLimit, auth, limiter, handler)
+
+	log.Printf("UNIX domain socket Endpoint created: unix://%s (%v)", path, mode)
+
+	return ep
+}
+
+var errChmod = errors.New("could not set the file mode of the UNIX domain socket")
+
+// listen creates the socket in a private temporary directory next to the path,
+// sets its file mode and then moves it to the path
+func listen(path string, mode os.FileMode) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".beacon-socket-") // only accessible by the owner
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	tmpPath := filepath.Join(dir, "socket")
+	l, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	listener := l.(*net.UnixListener)
+	listener.SetUnlinkOnClose(false) // the socket is moved, see socketListener
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("%w %s: %w", errChmod, path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return &socketListener{listener, path}, nil
+}
+
+// socketListener removes the socket at its final path when it is closed
+type socketListener struct {
+	*net.UnixListener
+	path string
+}
+
+func (l *socketListener) Close() error {
+	err := l.UnixListener.Close()
+	os.Remove(l.path)
+	return err
+}
+
+// removeStaleSocket removes a socket file left behind by a previous run that was not shut down cleanly.
+// Other kinds of files are never removed.
+func removeStaleSocket(path string) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return // does not exist
+	}
+	if info.Mode().Type() != os.ModeSocket {
+		log.Printf("%s exists and is not a socket, not removing it\n", path)
+		return
+	}
+	err = os.Remove(path)
+	if err != nil {
+		log.Printf("Could not remove stale socket %s: %v\n", path, err)
+		return
+	}
+	log.Printf("Removed stale socket %s\n", path)
+}
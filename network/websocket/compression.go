@@ -0,0 +1,170 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// CompressionStats measures the compression of the messages sent with permessage-deflate
+// by comparing the size of the messages with the size of the payloads of their frames (without framing, control messages and TLS)
+type CompressionStats struct {
+	MessageBytes atomic.Uint64 // uncompressed size of the sent messages
+	PayloadBytes atomic.Uint64 // size of the (compressed) payloads of the sent data frames
+}
+
+// Ratio returns the payload bytes per byte of the sent messages
+func (s *CompressionStats) Ratio() float64 {
+	messageBytes := s.MessageBytes.Load()
+	if messageBytes == 0 {
+		return 1
+	}
+	return float64(s.PayloadBytes.Load()) / float64(messageBytes)
+}
+
+func (s *CompressionStats) String() string {
+	return fmt.Sprintf("%d bytes of messages sent as %d bytes (ratio %.2f)", s.MessageBytes.Load(), s.PayloadBytes.Load(), s.Ratio())
+}
+
+// compressionStats are the CompressionStats of a connection that are also added to the stats of its endpoint
+type compressionStats struct {
+	CompressionStats
+	endpoint *CompressionStats
+	conn     *frameCountingConn
+}
+
+// newCompressionStats returns nil if compression is disabled or the client did not offer permessage-deflate
+// (i.e. compression was not negotiated)
+func newCompressionStats(request *http.Request, enabled bool, endpoint *CompressionStats) *compressionStats {
+	if !enabled || !offersDeflate(request) {
+		return nil
+	}
+	return &compressionStats{endpoint: endpoint}
+}
+
+// offersDeflate reports whether the permessage-deflate extension is offered in the upgrade request
+func offersDeflate(request *http.Request) bool {
+	for _, header := range request.Header.Values("Sec-Websocket-Extensions") {
+		for _, extension := range strings.Split(header, ",") {
+			name, _, _ := strings.Cut(extension, ";")
+			if strings.EqualFold(strings.TrimSpace(name), "permessage-deflate") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *compressionStats) messageSent(size int) {
+	s.MessageBytes.Add(uint64(size))
+	s.endpoint.MessageBytes.Add(uint64(size))
+}
+
+func (s *compressionStats) payloadSent(size uint64) {
+	s.PayloadBytes.Add(size)
+	s.endpoint.PayloadBytes.Add(size)
+}
+
+// responseWriter returns a response writer whose hijacked connection counts the payloads of the sent frames
+// (the frames are counted once start is called after the upgrade)
+func (s *compressionStats) responseWriter(w http.ResponseWriter) http.ResponseWriter {
+	return &countingResponseWriter{w, s}
+}
+
+// start counts the frames sent after the opening handshake
+func (s *compressionStats) start() {
+	if s.conn != nil {
+		s.conn.counting.Store(true)
+	}
+}
+
+type countingResponseWriter struct {
+	http.ResponseWriter
+	stats *compressionStats
+}
+
+func (w *countingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	w.stats.conn = &frameCountingConn{Conn: conn, stats: w.stats}
+	return w.stats.conn, rw, nil
+}
+
+// frameCountingConn parses the websocket frames written to the connection and counts the payloads of the data frames
+// (writes are never concurrent since the websocket connection allows only one writer)
+type frameCountingConn struct {
+	net.Conn
+	stats     *compressionStats
+	counting  atomic.Bool
+	header    []byte // received bytes of the current frame header
+	remaining uint64 // remaining payload bytes of the current frame
+	data      bool   // whether the current frame is a data frame
+}
+
+func (c *frameCountingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if c.counting.Load() {
+		c.parse(b[:n])
+	}
+	return n, err
+}
+
+func (c *frameCountingConn) parse(b []byte) {
+	for len(b) > 0 {
+		if c.remaining > 0 {
+			size := min(c.remaining, uint64(len(b)))
+			if c.data {
+				c.stats.payloadSent(size)
+			}
+			c.remaining -= size
+			b = b[size:]
+			continue
+		}
+		c.header = append(c.header, b[0])
+		b = b[1:]
+		if length, ok := parseFrameHeader(c.header); ok {
+			c.data = c.header[0]&0x0f < 0x08 // continuation, text and binary frames
+			c.remaining = length
+			c.header = c.header[:0]
+		}
+	}
+}
+
+// parseFrameHeader returns the payload length of a frame if the header is complete
+func parseFrameHeader(header []byte) (uint64, bool) {
+	if len(header) < 2 {
+		return 0, false
+	}
+	length := uint64(header[1] & 0x7f)
+	size := 2
+	switch length {
+	case 126:
+		size += 2
+	case 127:
+		size += 8
+	}
+	if header[1]&0x80 != 0 { // masked
+		size += 4
+	}
+	if len(header) < size {
+		return 0, false
+	}
+	switch length {
+	case 126:
+		length = uint64(binary.BigEndian.Uint16(header[2:4]))
+	case 127:
+		length = binary.BigEndian.Uint64(header[2:10])
+	}
+	return length, true
+}
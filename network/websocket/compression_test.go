@@ -0,0 +1,27 @@
+package websocket
+
+import (
+	"net"
+	"testing"
+)
+
+type discardConn struct{ net.Conn }
+
+func (discardConn) Write(b []byte) (int, error) { return len(b), nil }
+
+func TestFrameCountingConn(t *testing.T) {
+	stats := &compressionStats{endpoint: &CompressionStats{}}
+	conn := &frameCountingConn{Conn: discardConn{}, stats: stats}
+	conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\n\r\n")) // handshake is not counted
+	conn.counting.Store(true)
+	frames := []byte{0xc2, 3, 1, 2, 3}             // compressed binary frame
+	frames = append(frames, 0x89, 2, 0, 0)         // ping
+	frames = append(frames, 0x82, 126, 0x01, 0x00) // binary frame with 256 bytes
+	frames = append(frames, make([]byte, 256)...)
+	for i := 0; i < len(frames); i += 7 { // frames are split across writes
+		conn.Write(frames[i:min(i+7, len(frames))])
+	}
+	if stats.PayloadBytes.Load() != 259 || stats.endpoint.PayloadBytes.Load() != 259 {
+		t.Fatalf("expected 259 payload bytes, got %d", stats.PayloadBytes.Load())
+	}
+}
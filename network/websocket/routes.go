@@ -0,0 +1,64 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/ProjectLighthouseCAU/beacon/auth"
+	"github.com/ProjectLighthouseCAU/beacon/config"
+)
+
+// Route mounts the websocket endpoint on a path with its own auth
+type Route struct {
+	Path string
+	Auth auth.Auth
+}
+
+// RouteConfig configures a route in config.WebsocketRoutesJson, e.g.:
+//
+//	[
+//	  {"path": "/websocket", "auth": "heimdall"},
+//	  {"path": "/internal", "auth": "hardcoded"},
+//	  {"path": "/public", "auth": "allow_all", "read_only": true, "subtree": ["live"]}
+//	]
+type RouteConfig struct {
+	Path     string   `json:"path"`
+	Auth     string   `json:"auth"`      // name of the auth implementation (see config.Auth), defaults to config.Auth
+	ReadOnly bool     `json:"read_only"` // only allow read operations
+	Subtree  []string `json:"subtree"`   // only allow requests on paths in this subtree of the directory
+}
+
+// RouteConfigs returns the configured routes (config.WebsocketRoutesJson)
+// or a single route on config.WebsocketRoute with config.Auth if no routes are configured.
+// Exits if the configured routes cannot be parsed.
+func RouteConfigs() []RouteConfig {
+	var routeConfigs []RouteConfig
+	if config.WebsocketRoutesJson != "" {
+		err := json.Unmarshal([]byte(config.WebsocketRoutesJson), &routeConfigs)
+		if err != nil {
+			log.Fatalln("Could not parse WEBSOCKET_ROUTES_JSON:", err)
+		}
+	}
+	if len(routeConfigs) == 0 {
+		routeConfigs = append(routeConfigs, RouteConfig{Path: config.WebsocketRoute})
+	}
+	for i := range routeConfigs {
+		if routeConfigs[i].Auth == "" {
+			routeConfigs[i].Auth = config.Auth
+		}
+	}
+	return routeConfigs
+}
+
+// Route creates the route with the auth implementation of the given name (created by createAuth)
+// restricted to read operations and/or a subtree of the directory
+func (c RouteConfig) Route(createAuth func(name string) auth.Auth) Route {
+	routeAuth := createAuth(c.Auth)
+	if c.ReadOnly {
+		routeAuth = auth.NewReadOnlyAuth(routeAuth)
+	}
+	if len(c.Subtree) > 0 {
+		routeAuth = auth.NewSubtreeAuth(routeAuth, c.Subtree)
+	}
+	return Route{Path: c.Path, Auth: routeAuth}
+}
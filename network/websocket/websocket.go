@@ -3,49 +3,53 @@ package websocket
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/ProjectLighthouseCAU/beacon/auth"
 	"github.com/ProjectLighthouseCAU/beacon/config"
 	"github.com/ProjectLighthouseCAU/beacon/handler"
 	"github.com/ProjectLighthouseCAU/beacon/network"
+	"github.com/ProjectLighthouseCAU/beacon/ratelimit"
 	"github.com/ProjectLighthouseCAU/beacon/types"
 
 	"github.com/gorilla/websocket"
 )
 
-// Endpoint defines a websocket endpoint
-type Endpoint struct { // extends BaseEndpoint implements network.Endpoint (reminder for Java-Dev)
-	network.BaseEndpoint // extends
-	httpServer           *http.Server
-	upgrader             websocket.Upgrader
-	connectedClients     map[*types.Client]*websocket.Conn
-	clientsLock          sync.Mutex
+// Endpoint defines a websocket endpoint that serves one or more routes on the same port
+type Endpoint struct { // implements network.Endpoint (reminder for Java-Dev)
+	routes     []*route
+	httpServer *http.Server
+	upgrader   websocket.Upgrader
+	handlers   sync.WaitGroup // running connection handlers
+
+	Compression CompressionStats // compression of the messages sent to clients that negotiated permessage-deflate
 }
 
 var _ network.Endpoint = (*Endpoint)(nil) // implements
 
-// CreateEndpoint initiates the websocket endpoint (blocking call)
-func CreateEndpoint(host string, port int, auth auth.Auth, handler *handler.Handler) *Endpoint {
+// route is a path of the websocket endpoint with its own auth
+type route struct { // extends BaseEndpoint
+	network.BaseEndpoint // extends
+	path                 string
+}
+
+// CreateEndpoint initiates the websocket endpoint with the given routes (blocking call)
+func CreateEndpoint(host string, port int, routes []Route, limiter *ratelimit.Limiter, handler *handler.Handler) *Endpoint {
 
 	defer func() { // recover from any panic during initialization and retry
 		if r := recover(); r != nil {
 			log.Println("Error while creating websocket endpoint: ", r)
 			log.Println("Retrying in 3 seconds...")
 			time.Sleep(3 * time.Second)
-			CreateEndpoint(host, port, auth, handler)
+			CreateEndpoint(host, port, routes, limiter, handler)
 		}
 	}()
 
 	ep := &Endpoint{
-		BaseEndpoint: network.BaseEndpoint{
-			Type:    network.Websocket,
-			Auth:    auth,
-			Handler: handler,
-		},
 		httpServer: &http.Server{Addr: fmt.Sprintf("%s:%d", host, port)},
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  config.WebsocketReadBufferSize,
@@ -53,17 +57,34 @@ func CreateEndpoint(host string, port int, auth auth.Auth, handler *handler.Hand
 			CheckOrigin: func(r *http.Request) bool {
 				return true // allow websocket connections from all origin domains
 			},
+			Subprotocols:      []string{SubprotocolMsgpack, SubprotocolJSON},
+			EnableCompression: config.WebsocketCompression, // permessage-deflate (if the client supports it)
 		},
-		connectedClients: make(map[*types.Client]*websocket.Conn),
 	}
-	ep.httpServer.Handler = ep.getWebsocketHandler()
+	mux := http.NewServeMux()
+	for _, r := range routes {
+		route := &route{
+			BaseEndpoint: network.BaseEndpoint{
+				Type:        network.Websocket,
+				Auth:        r.Auth,
+				RateLimiter: limiter,
+				Handler:     handler,
+			},
+			path: r.Path,
+		}
+		ep.routes = append(ep.routes, route)
+		mux.Handle(r.Path, ep.getWebsocketHandler(route))
+	}
+	ep.httpServer.Handler = mux
 	go func() {
-		if err := ep.httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		if err := network.ListenAndServe(ep.httpServer); err != http.ErrServerClosed {
 			log.Panicf("ListenAndServe returned: %v", err)
 		}
 	}()
 
-	log.Printf("WebSocket Endpoint created: ws://%s:%d", host, port)
+	for _, route := range ep.routes {
+		log.Printf("WebSocket Endpoint created: %s://%s:%d%s", network.HttpScheme(true), host, port, route.path)
+	}
 
 	return ep
 }
@@ -71,17 +92,24 @@ func CreateEndpoint(host string, port int, auth auth.Auth, handler *handler.Hand
 // Close closes the WebSocket Endpoint
 func (ep *Endpoint) Close() {
 	log.Println("Closing websocket endpoint")
-	ep.clientsLock.Lock()
-	defer ep.clientsLock.Unlock()
-	for client, conn := range ep.connectedClients {
-		log.Println("Disconnecting ", client.Ip())
-		// TODO: disconnect client gracefully using close-message
-		// with code CloseServiceRestart
-		ep.disconnectClient(client, conn, true)
-	}
-	// delete all connected clients for good measure
-	ep.connectedClients = make(map[*types.Client]*websocket.Conn)
+	for _, route := range ep.routes {
+		route.DisconnectAll(network.CloseServiceRestart, "server restart")
+	}
+	// wait for the clients to answer the close messages
+	handlersDone := make(chan struct{})
+	go func() {
+		ep.handlers.Wait()
+		close(handlersDone)
+	}()
+	select {
+	case <-handlersDone:
+	case <-time.After(config.WebsocketCloseTimeout + time.Second):
+		log.Println("Timed out waiting for clients to close their connections")
+	}
 	log.Println("All clients disconnected")
+	if ep.upgrader.EnableCompression {
+		log.Println("Websocket compression statistics:", &ep.Compression)
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 	err := ep.httpServer.Shutdown(ctx)
@@ -93,110 +121,238 @@ func (ep *Endpoint) Close() {
 	log.Println("Websocket endpoint closed")
 }
 
-func (ep *Endpoint) disconnectClient(client *types.Client, conn *websocket.Conn, alreadyLocked bool) {
-	// TODO: using a re-entrant lock would be much nicer here
-	if !alreadyLocked {
-		ep.clientsLock.Lock()
-		delete(ep.connectedClients, client)
-		ep.clientsLock.Unlock()
-	}
-	client.Disconnect(ep.Handler.GetDirectory())
-	conn.Close()
-	log.Println("Client disconnected: ", client.Ip())
-}
-
-// The websocket handler upgrades HTTP to WebSocket connections, creates a new Client for that connection
-// and then reads and decodes received packets into a Request before forwarding to the handler package.
-func (ep *Endpoint) getWebsocketHandler() http.HandlerFunc {
+// The websocket handler upgrades HTTP to WebSocket connections
+// and passes them to the request pipeline of the route.
+func (ep *Endpoint) getWebsocketHandler(route *route) http.HandlerFunc {
 	return func(responseWriter http.ResponseWriter, request *http.Request) {
-		defer func() {
-			if r := recover(); r != nil {
-				log.Println("Error while handling websocket connection: ", r)
-				log.Println("Closing...")
-			}
-		}()
+		ep.handlers.Add(1)
+		defer ep.handlers.Done()
 
-		clientIp := request.Header.Get("X-Real-Ip")
-		if clientIp == "" {
-			clientIp = request.Header.Get("X-Forwarded-For")
-		}
-		if clientIp == "" {
-			clientIp = request.RemoteAddr
-		}
+		clientIp := network.GetClientIp(request)
 
 		log.Printf("Incoming Connection from: %s\n", clientIp)
 
+		if err := network.AcquireConnection(clientIp); err != nil {
+			log.Printf("Rejected connection from %s: %v\n", clientIp, err)
+			network.RejectConnection(responseWriter, err)
+			return
+		}
+		defer network.ReleaseConnection(clientIp)
+
+		compression := newCompressionStats(request, ep.upgrader.EnableCompression, &ep.Compression)
+		if compression != nil {
+			responseWriter = compression.responseWriter(responseWriter)
+		}
 		conn, err := ep.upgrader.Upgrade(responseWriter, request, nil)
 		if err != nil {
 			log.Println(err)
 			return
 		}
-		conn.SetReadLimit(int64(config.WebsocketReadLimit)) // set the maximum message size -> closes connection if exceeded
+		if compression != nil {
+			compression.start()
+		}
+		conn.SetReadLimit(int64(config.WebsocketReadLimit)) // set the maximum message size on the wire -> closes connection if exceeded (the decompressed size is limited by wsConn.readMessage)
+		if err := conn.SetCompressionLevel(config.WebsocketCompressionLevel); err != nil {
+			log.Println("Invalid websocket compression level:", err)
+		}
+
+		wsConn := newWsConn(conn, network.CertificateUser(request))
+		wsConn.compression = compression
+		route.Serve(wsConn, clientIp)
+		wsConn.awaitClose()
+		if wsConn.compression != nil {
+			log.Printf("Websocket compression of %s: %s\n", clientIp, wsConn.compression)
+		}
+	}
+}
 
-		client := types.NewClient(clientIp, getSendFunc(conn))
-		ep.clientsLock.Lock()
-		ep.connectedClients[client] = conn
-		ep.clientsLock.Unlock()
+// Subprotocols for selecting the encoding of a connection.
+// Without a subprotocol, the encoding is determined by the type of the first received message.
+const (
+	SubprotocolMsgpack = "lighthouse-msgpack" // binary messages containing MessagePack
+	SubprotocolJSON    = "lighthouse-json"    // text messages containing JSON
+)
 
-		for {
-			messageType, payload, err := conn.ReadMessage()
-			if err != nil {
-				ep.disconnectClient(client, conn, false)
-				return
-			}
+// encoding modes of a websocket connection
+const (
+	modeUndetermined int32 = iota
+	modeMsgpack
+	modeJSON
+)
 
-			if messageType != websocket.BinaryMessage {
-				response := types.NewResponse().Reid([]byte{0}).Rnum(http.StatusBadRequest).Warning("Non binary-type message received, use websocket binary-type instead").Build()
-				client.Send(response)
-				// TODO: send close-message: CloseUnsupportedData
-				ep.disconnectClient(client, conn, false)
-				return
-			}
-			request := types.Request{}
-			_, err = request.UnmarshalMsg(payload)
+// wsConn implements network.Conn for websocket connections.
+// In JSON mode, text messages are converted from JSON to MessagePack before they enter the request pipeline
+// and responses are converted back to JSON and sent as text messages.
+// The connection is kept alive with ping messages, if the client does not answer with a pong
+// (or any other message) within config.WebsocketPongTimeout, the connection is considered dead.
+// Closing the connection performs the websocket closing handshake (see Close and awaitClose).
+type wsConn struct {
+	conn        *websocket.Conn
+	certUser    string
+	compression *compressionStats // nil if compression is not used
+	mode        atomic.Int32
+	closing     atomic.Bool   // true once the close message was sent
+	closed      chan struct{} // closed once the close message was sent (stops the ping loop)
+	closeOnce   sync.Once
+}
+
+var _ network.CertificateConn = (*wsConn)(nil)
+var _ network.EncodingConn = (*wsConn)(nil)
+
+var (
+	errNonBinaryMessage = fmt.Errorf("%w: non binary-type message received, use websocket binary-type instead", network.ErrUnsupportedData)
+	errNonTextMessage   = fmt.Errorf("%w: non text-type message received, use websocket text-type for JSON instead", network.ErrUnsupportedData)
+)
+
+func newWsConn(conn *websocket.Conn, certUser string) *wsConn {
+	c := &wsConn{
+		conn:     conn,
+		certUser: certUser,
+		closed:   make(chan struct{}),
+	}
+	switch conn.Subprotocol() {
+	case SubprotocolMsgpack:
+		c.mode.Store(modeMsgpack)
+	case SubprotocolJSON:
+		c.mode.Store(modeJSON)
+	}
+	conn.SetReadDeadline(time.Now().Add(config.WebsocketPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		if c.closing.Load() {
+			return nil // keep the close timeout
+		}
+		return conn.SetReadDeadline(time.Now().Add(config.WebsocketPongTimeout))
+	})
+	go c.pingLoop()
+	return c
+}
+
+// pingLoop periodically sends ping messages until the connection is closed
+func (c *wsConn) pingLoop() {
+	ticker := time.NewTicker(config.WebsocketPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+			err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(config.WebsocketPingInterval))
 			if err != nil {
-				response := types.NewResponse().Reid(request.REID).Rnum(http.StatusBadRequest).Warning("Could not deserialize request. Please make sure that you are using the Lighthouse-Protocol correctly").Build()
-				client.Send(response)
-				// TODO: send close-message: CloseInvalidFramePayloadData
-				ep.disconnectClient(client, conn, false)
 				return
 			}
-
-			// authentication and authorization
-			if ok, code := ep.Auth.IsAuthorized(client, &request); !ok {
-				response := types.NewResponse().Reid(request.REID).Rnum(code).Build()
-				client.Send(response)
-				// TODO: decide when to disconnect client connection (without any authentication after timeout?)
-				// if code == http.StatusUnauthorized {
-				// 	disconnectClient()
-				// 	return
-				// }
-				continue
-			}
-			ep.Handler.HandleRequest(client, &request)
 		}
 	}
 }
 
-// This function wraps a reference to the websocket connection
-// and a mutex lock for synchronous access to that connection into a closure
-// and returns a function that takes a server.Response and writes it thread-safe to the websocket connection.
-func getSendFunc(connection *websocket.Conn) func(*types.Response) error {
+func (c *wsConn) Read() ([]byte, error) {
+	messageType, payload, err := c.readMessage()
+	for err == nil && c.closing.Load() {
+		// discard messages until the client answers the close message
+		messageType, payload, err = c.readMessage()
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.conn.SetReadDeadline(time.Now().Add(config.WebsocketPongTimeout))
+	// the first message determines the mode if no subprotocol was negotiated
+	if c.mode.Load() == modeUndetermined {
+		if messageType == websocket.TextMessage {
+			c.mode.Store(modeJSON)
+		} else {
+			c.mode.Store(modeMsgpack)
+		}
+	}
+	if c.mode.Load() == modeJSON {
+		if messageType != websocket.TextMessage {
+			return nil, errNonTextMessage
+		}
+		payload, err = types.JSONToMsgpack(payload)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid JSON: %w", network.ErrUnsupportedData, err)
+		}
+		return payload, nil
+	}
+	if messageType != websocket.BinaryMessage {
+		return nil, errNonBinaryMessage
+	}
+	return payload, nil
+}
 
-	var lock = &sync.Mutex{}
+// readMessage reads the next message and fails with network.ErrReadLimitExceeded if it is larger than config.WebsocketReadLimit.
+// The limit of the websocket connection only applies to the bytes on the wire, which are compressed with permessage-deflate,
+// so the size of the decompressed message is limited here.
+func (c *wsConn) readMessage() (int, []byte, error) {
+	messageType, reader, err := c.conn.NextReader()
+	if err != nil {
+		return messageType, nil, err
+	}
+	payload, err := io.ReadAll(io.LimitReader(reader, int64(config.WebsocketReadLimit)+1))
+	if err != nil {
+		return messageType, nil, err
+	}
+	if len(payload) > config.WebsocketReadLimit {
+		return messageType, nil, network.ErrReadLimitExceeded
+	}
+	return messageType, payload, nil
+}
 
-	return func(response *types.Response) error {
-		data, err := response.MarshalMsg(nil)
+func (c *wsConn) Write(data []byte) error {
+	messageType := websocket.BinaryMessage
+	if c.mode.Load() == modeJSON {
+		json, err := types.MsgpackToJSON(data)
 		if err != nil {
 			return err
 		}
+		messageType, data = websocket.TextMessage, json
+	}
+	// compressing small messages is not worth it (no-op if compression was not negotiated)
+	c.conn.EnableWriteCompression(len(data) >= config.WebsocketCompressionMinSize)
+	if c.compression != nil {
+		c.compression.messageSent(len(data))
+	}
+	return c.conn.WriteMessage(messageType, data)
+}
+
+func (c *wsConn) CertificateUser() string {
+	return c.certUser
+}
+
+func (c *wsConn) SetJSON(json bool) {
+	if json {
+		c.mode.Store(modeJSON)
+	} else {
+		c.mode.Store(modeMsgpack)
+	}
+}
 
-		lock.Lock()
-		err = connection.WriteMessage(websocket.BinaryMessage, data)
-		lock.Unlock()
+// Close starts the closing handshake by sending a close message with the given code and reason.
+// The connection is closed by awaitClose as soon as the client answers or config.WebsocketCloseTimeout passed.
+func (c *wsConn) Close(code network.CloseCode, reason string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.closing.Store(true)
+		close(c.closed)
+		deadline := time.Now().Add(config.WebsocketCloseTimeout)
+		closeMessage := websocket.FormatCloseMessage(int(code), reason)
+		err = c.conn.WriteControl(websocket.CloseMessage, closeMessage, deadline)
 		if err != nil {
-			connection.Close()
+			// the client closed the connection or cannot receive the close message anyway
+			err = c.conn.Close()
+			return
+		}
+		err = c.conn.SetReadDeadline(deadline)
+	})
+	return err
+}
+
+// awaitClose discards incoming messages until reading fails, i.e. the client answered the close message
+// (or the close timeout passed), and closes the underlying connection afterwards.
+// Must be called after the request pipeline stopped reading from the connection.
+func (c *wsConn) awaitClose() {
+	for {
+		if _, _, err := c.conn.NextReader(); err != nil {
+			break
 		}
-		return err
 	}
+	c.conn.Close()
 }
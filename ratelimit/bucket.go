@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"time"
+)
+
+// bucket is a token bucket that is refilled with rate tokens per second up to burst tokens
+type bucket struct {
+	rate     float64
+	burst    float64
+	tokens   float64
+	lastFill time.Time
+}
+
+func newBucket(limit Limit, now time.Time) *bucket {
+	burst := float64(max(limit.Burst, 1))
+	return &bucket{
+		rate:     limit.Rate,
+		burst:    burst,
+		tokens:   burst,
+		lastFill: now,
+	}
+}
+
+// fill adds the tokens accumulated since the last fill
+func (b *bucket) fill(now time.Time) {
+	if elapsed := now.Sub(b.lastFill).Seconds(); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed*b.rate)
+	}
+	b.lastFill = now
+}
+
+// retryAfter returns the time until a token is available (0 if there is one)
+func (b *bucket) retryAfter(now time.Time) time.Duration {
+	b.fill(now)
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// take removes a token (only call if retryAfter returned 0)
+func (b *bucket) take() {
+	b.tokens--
+}
+
+// full reports whether the bucket is completely refilled (and can therefore be discarded)
+func (b *bucket) full(now time.Time) bool {
+	b.fill(now)
+	return b.tokens >= b.burst
+}
@@ -0,0 +1,176 @@
+// Package ratelimit limits the number of requests per connection and per authenticated user using token buckets.
+package ratelimit
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+// Limit allows Rate requests per second on average and bursts of up to Burst requests.
+// A Rate <= 0 means unlimited.
+type Limit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// Limits maps a VERB (or "*" for all verbs without their own limit) to its limit.
+// Verbs without a limit are unlimited.
+type Limits map[string]Limit
+
+// lookup returns the limit of the verb and the key of its bucket
+func (l Limits) lookup(verb string) (limit Limit, key string, ok bool) {
+	if limit, ok := l[verb]; ok {
+		return limit, verb, limit.Rate > 0
+	}
+	if limit, ok := l["*"]; ok {
+		return limit, "*", limit.Rate > 0
+	}
+	return Limit{}, "", false
+}
+
+// Config contains the limits per connection and per user, e.g.:
+//
+//	{
+//	  "connection": {"*": {"rate": 100, "burst": 200}},
+//	  "user": {"PUT": {"rate": 60, "burst": 120}, "LIST": {"rate": 1, "burst": 5}},
+//	  "roles": {"admin": {}}
+//	}
+type Config struct {
+	Connection Limits `json:"connection"` // limits of every connection
+	User       Limits `json:"user"`       // limits of every user (shared by all connections of the user)
+	// user limits that replace the default user limits for users with the role
+	// (the first role of the user that is configured is used)
+	Roles map[string]Limits `json:"roles"`
+}
+
+// ParseConfig parses the JSON rate limit config (see Config), returns an empty config (no limits) on error
+func ParseConfig(configJson string) Config {
+	var config Config
+	err := json.Unmarshal([]byte(configJson), &config)
+	if err != nil {
+		log.Println("Could not parse rate limit config:", err)
+		return Config{}
+	}
+	return config
+}
+
+// interval in which buckets that are completely refilled are discarded
+const sweepInterval = time.Minute
+
+// Limiter keeps the token buckets of all connections and users.
+// A nil Limiter allows all requests.
+type Limiter struct {
+	config Config
+
+	lock        sync.Mutex
+	connections map[*types.Client]map[string]*bucket
+	users       map[string]map[string]*bucket
+	lastSweep   time.Time
+}
+
+func New(config Config) *Limiter {
+	return &Limiter{
+		config:      config,
+		connections: make(map[*types.Client]map[string]*bucket),
+		users:       make(map[string]map[string]*bucket),
+		lastSweep:   time.Now(),
+	}
+}
+
+// Allow takes a token from the buckets of the connection and the user of an authorized request.
+// If any of them is empty, the request is denied and the time after which it can be retried is returned.
+func (l *Limiter) Allow(client *types.Client, request *types.Request) (ok bool, retryAfter time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	return l.allow(client, request, time.Now())
+}
+
+func (l *Limiter) allow(client *types.Client, request *types.Request, now time.Time) (bool, time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if now.Sub(l.lastSweep) > sweepInterval {
+		l.sweep(now)
+	}
+
+	buckets := make([]*bucket, 0, 2)
+	if limit, key, ok := l.config.Connection.lookup(request.VERB); ok {
+		buckets = append(buckets, getBucket(l.connections, client, key, limit, now))
+	}
+	if username, ok := request.AUTH["USER"]; ok {
+		if limit, key, ok := l.userLimits(client, username).lookup(request.VERB); ok {
+			buckets = append(buckets, getBucket(l.users, username, key, limit, now))
+		}
+	}
+
+	var retryAfter time.Duration
+	for _, b := range buckets {
+		retryAfter = max(retryAfter, b.retryAfter(now))
+	}
+	if retryAfter > 0 {
+		return false, retryAfter
+	}
+	for _, b := range buckets {
+		b.take()
+	}
+	return true, 0
+}
+
+// userLimits returns the limits of the first configured role of the user or the default user limits.
+// The roles are only known if the auth implementation stores them in the auth cache of the client (e.g. heimdall).
+func (l *Limiter) userLimits(client *types.Client, username string) Limits {
+	if len(l.config.Roles) > 0 {
+		if entry := client.LookupAuthCache(username); entry != nil {
+			for _, role := range entry.Roles {
+				if limits, ok := l.config.Roles[role]; ok {
+					return limits
+				}
+			}
+		}
+	}
+	return l.config.User
+}
+
+func getBucket[K comparable](buckets map[K]map[string]*bucket, owner K, key string, limit Limit, now time.Time) *bucket {
+	ownerBuckets, ok := buckets[owner]
+	if !ok {
+		ownerBuckets = make(map[string]*bucket)
+		buckets[owner] = ownerBuckets
+	}
+	b, ok := ownerBuckets[key]
+	if !ok || b.rate != limit.Rate { // the limit of a user changes with its roles
+		b = newBucket(limit, now)
+		ownerBuckets[key] = b
+	}
+	return b
+}
+
+// Forget removes the buckets of a disconnected client
+func (l *Limiter) Forget(client *types.Client) {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	delete(l.connections, client)
+	l.lock.Unlock()
+}
+
+// sweep discards the buckets of users that are completely refilled (they behave like new buckets)
+func (l *Limiter) sweep(now time.Time) {
+	for username, buckets := range l.users {
+		for key, b := range buckets {
+			if b.full(now) {
+				delete(buckets, key)
+			}
+		}
+		if len(buckets) == 0 {
+			delete(l.users, username)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ProjectLighthouseCAU/beacon/types"
+)
+
+func request(verb, user string) *types.Request {
+	auth := map[string]string{}
+	if user != "" {
+		auth["USER"] = user
+	}
+	return &types.Request{VERB: verb, AUTH: auth}
+}
+
+func TestConnectionLimit(t *testing.T) {
+	l := New(ParseConfig(`{"connection": {"PUT": {"rate": 2, "burst": 2}}}`))
+	client := types.NewClient("", nil)
+	now := time.Now()
+	for i := range 2 {
+		if ok, _ := l.allow(client, request("PUT", ""), now); !ok {
+			t.Fatalf("request %d within burst denied", i)
+		}
+	}
+	ok, retryAfter := l.allow(client, request("PUT", ""), now)
+	if ok || retryAfter != 500*time.Millisecond {
+		t.Fatalf("expected denial with retry after 500ms, got %v %s", ok, retryAfter)
+	}
+	if ok, _ := l.allow(client, request("GET", ""), now); !ok {
+		t.Fatal("verb without limit denied")
+	}
+	if ok, _ := l.allow(types.NewClient("", nil), request("PUT", ""), now); !ok {
+		t.Fatal("other connection denied")
+	}
+	if ok, _ := l.allow(client, request("PUT", ""), now.Add(retryAfter)); !ok {
+		t.Fatal("request after refill denied")
+	}
+}
+
+func TestUserLimitAcrossConnections(t *testing.T) {
+	l := New(ParseConfig(`{"user": {"*": {"rate": 1, "burst": 1}}}`))
+	now := time.Now()
+	if ok, _ := l.allow(types.NewClient("", nil), request("LIST", "alice"), now); !ok {
+		t.Fatal("first request denied")
+	}
+	if ok, _ := l.allow(types.NewClient("", nil), request("PUT", "alice"), now); ok {
+		t.Fatal("second request of the same user on another connection allowed")
+	}
+	if ok, _ := l.allow(types.NewClient("", nil), request("PUT", "bob"), now); !ok {
+		t.Fatal("request of another user denied")
+	}
+}
+
+func TestRoleLimits(t *testing.T) {
+	l := New(ParseConfig(`{"user": {"*": {"rate": 1, "burst": 1}}, "roles": {"admin": {}}}`))
+	client := types.NewClient("", nil)
+	client.SetAuthCacheEntry("root", &types.AuthCacheEntry{Roles: []string{"admin"}})
+	now := time.Now()
+	for i := range 10 {
+		if ok, _ := l.allow(client, request("PUT", "root"), now); !ok {
+			t.Fatalf("request %d of unlimited role denied", i)
+		}
+	}
+}
@@ -29,7 +29,8 @@ type broker[T any] struct {
 	streams map[chan T]bool       // keeps track of active subscriber streams (value indicates whether the channel is infinite->blocking-send or finite->non-blocking-send)
 	links   map[*broker[T]]chan T // keeps track of active links from other resources
 
-	value     T // latest input value
+	value     T      // latest input value
+	version   uint64 // incremented with every input value
 	valueLock sync.RWMutex
 }
 
@@ -37,13 +38,15 @@ var _ resource.Resource[resource.Content] = (*broker[resource.Content])(nil) //
 
 // Response struct for detailed response to the server
 type response struct {
-	Code int
-	Err  error
+	Code    int
+	Err     error
+	Version uint64 // version after PUT
 }
 
 // Message sent through input channel
 type inputMsg[T any] struct { // PUT
 	Content      T
+	IfVersion    *uint64 // only update if the current version matches (nil: always update)
 	ResponseChan chan response
 }
 
@@ -119,7 +122,15 @@ func (r *broker[T]) broker() {
 		case inputMsg := <-r.input: // input message (PUT)
 			payload := inputMsg.Content
 			r.valueLock.Lock()
+			if inputMsg.IfVersion != nil && *inputMsg.IfVersion != r.version {
+				version := r.version
+				r.valueLock.Unlock()
+				inputMsg.ResponseChan <- response{Code: 412, Err: resource.ErrVersionMismatch, Version: version}
+				break
+			}
 			r.value = payload
+			r.version++
+			version := r.version
 			r.valueLock.Unlock()
 			// send new value to all subscribed streams
 			anyStreamSkipped := false
@@ -135,9 +146,9 @@ func (r *broker[T]) broker() {
 				}
 			}
 			if anyStreamSkipped {
-				inputMsg.ResponseChan <- response{Code: 200, Err: resource.ErrWarnStreamSkipped}
+				inputMsg.ResponseChan <- response{Code: 200, Err: resource.ErrWarnStreamSkipped, Version: version}
 			} else {
-				inputMsg.ResponseChan <- response{Code: 200, Err: nil}
+				inputMsg.ResponseChan <- response{Code: 200, Err: nil, Version: version}
 			}
 
 		case controlMsg := <-r.control: // control message (CLOSE, STREAM, STOP, LINK, UNLINK)
@@ -246,11 +257,17 @@ func (r *broker[T]) StopStream(stream chan T) error {
 
 // Put updates the value of this resource.
 func (r *broker[T]) Put(payload T) error {
+	_, err := r.PutVersioned(payload, nil)
+	return err
+}
+
+// PutVersioned updates the value of this resource if the version matches (or ifVersion is nil) and returns the new version.
+func (r *broker[T]) PutVersioned(payload T, ifVersion *uint64) (uint64, error) {
 	respChan := make(chan response)
 	defer close(respChan)
-	r.input <- inputMsg[T]{Content: payload, ResponseChan: respChan}
+	r.input <- inputMsg[T]{Content: payload, IfVersion: ifVersion, ResponseChan: respChan}
 	resp := <-respChan
-	return resp.Err
+	return resp.Version, resp.Err
 }
 
 // Get returns the current (latest written) value of this resource
@@ -260,6 +277,13 @@ func (r *broker[T]) Get() T {
 	return r.value
 }
 
+// GetVersioned returns the current value of this resource and its version
+func (r *broker[T]) GetVersioned() (T, uint64) {
+	r.valueLock.RLock()
+	defer r.valueLock.RUnlock()
+	return r.value, r.version
+}
+
 // Link links one resources input to another resources output.
 // The link fails if it causes a loop in the linking graph.
 func (r *broker[T]) Link(other resource.Resource[T]) error {
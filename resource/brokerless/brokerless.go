@@ -19,12 +19,18 @@ type brokerless[T any] struct {
 	linksLock sync.Mutex
 
 	value     T // exported for serialization during snapshotting
+	version   uint64
 	valueLock sync.RWMutex
 }
 
 var _ resource.Resource[resource.Content] = (*brokerless[resource.Content])(nil)
 
 func Create[T any](path []string, initialValue T) resource.Resource[T] {
+	return CreateVersioned(path, initialValue, 0)
+}
+
+// CreateVersioned creates a resource whose value already has a version (e.g. when restoring a snapshot)
+func CreateVersioned[T any](path []string, initialValue T, version uint64) resource.Resource[T] {
 	return &brokerless[T]{
 		path:        path,
 		streams:     make(map[chan T]struct{}),
@@ -32,6 +38,7 @@ func Create[T any](path []string, initialValue T) resource.Resource[T] {
 		links:       make(map[*brokerless[T]]struct{}),
 		linksLock:   sync.Mutex{},
 		value:       initialValue,
+		version:     version,
 		valueLock:   sync.RWMutex{},
 	}
 }
@@ -61,10 +68,30 @@ func (r *brokerless[T]) Get() T {
 	return r.value
 }
 
+// GetVersioned implements resource.Resource.
+func (r *brokerless[T]) GetVersioned() (T, uint64) {
+	r.valueLock.RLock()
+	defer r.valueLock.RUnlock()
+	return r.value, r.version
+}
+
 // Put implements resource.Resource.
 func (r *brokerless[T]) Put(value T) error {
+	_, err := r.PutVersioned(value, nil)
+	return err
+}
+
+// PutVersioned implements resource.Resource.
+func (r *brokerless[T]) PutVersioned(value T, ifVersion *uint64) (uint64, error) {
 	r.valueLock.Lock()
+	if ifVersion != nil && *ifVersion != r.version {
+		version := r.version
+		r.valueLock.Unlock()
+		return version, resource.ErrVersionMismatch
+	}
 	r.value = value
+	r.version++
+	version := r.version
 	r.valueLock.Unlock()
 	// TODO: if all streams and links should receive the values in the same order, we need to lock them
 	anyStreamSkipped := false
@@ -83,9 +110,9 @@ func (r *brokerless[T]) Put(value T) error {
 		link.Put(value)
 	}
 	if anyStreamSkipped {
-		return resource.ErrWarnStreamSkipped
+		return version, resource.ErrWarnStreamSkipped
 	}
-	return nil
+	return version, nil
 }
 
 // Stream implements resource.Resource.
@@ -0,0 +1,132 @@
+package resource
+
+import (
+	"errors"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+var ErrPatchNotMap = errors.New("patch must be a map")
+
+// Merge applies a patch (a MessagePack map) to a value and returns the merged value (similar to JSON Merge Patch, RFC 7386):
+//   - keys of the patch that are nil are deleted from the value
+//   - maps in the patch are merged recursively into the maps of the value with the same key
+//   - all other values of the patch (including arrays) replace the values with the same key
+//
+// If the value is not a map, it is replaced by the patch (without its nil keys).
+// Keys that are not changed by the patch keep their encoding and order.
+func Merge(value Content, patch Content) (Content, error) {
+	if msgp.NextType(patch) != msgp.MapType {
+		return nil, ErrPatchNotMap
+	}
+	patchEntries, err := readMap(patch)
+	if err != nil {
+		return nil, err
+	}
+	var valueEntries []mapEntry
+	if msgp.NextType(value) == msgp.MapType {
+		valueEntries, err = readMap(value)
+		if err != nil { // invalid values are replaced
+			valueEntries = nil
+		}
+	}
+	merged, err := mergeMaps(valueEntries, patchEntries)
+	if err != nil {
+		return nil, err
+	}
+	return writeMap(merged), nil
+}
+
+// mapEntry is a key-value pair of an encoded map
+type mapEntry struct {
+	id    string // identifies the key independently of its encoding (e.g. str8 and fixstr)
+	key   msgp.Raw
+	value msgp.Raw
+}
+
+func mergeMaps(entries []mapEntry, patch []mapEntry) ([]mapEntry, error) {
+	for _, p := range patch {
+		i := findEntry(entries, p.id)
+		if msgp.IsNil(p.value) { // delete
+			if i >= 0 {
+				entries = append(entries[:i], entries[i+1:]...)
+			}
+			continue
+		}
+		value := p.value
+		if msgp.NextType(p.value) == msgp.MapType { // merge recursively
+			patchEntries, err := readMap(p.value)
+			if err != nil {
+				return nil, err
+			}
+			var valueEntries []mapEntry
+			if i >= 0 && msgp.NextType(entries[i].value) == msgp.MapType {
+				valueEntries, _ = readMap(entries[i].value)
+			}
+			merged, err := mergeMaps(valueEntries, patchEntries)
+			if err != nil {
+				return nil, err
+			}
+			value = msgp.Raw(writeMap(merged))
+		}
+		if i >= 0 {
+			entries[i].value = value
+		} else {
+			entries = append(entries, mapEntry{id: p.id, key: p.key, value: value})
+		}
+	}
+	return entries, nil
+}
+
+func findEntry(entries []mapEntry, id string) int {
+	for i, entry := range entries {
+		if entry.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// readMap splits an encoded map into its encoded keys and values
+func readMap(b []byte) ([]mapEntry, error) {
+	size, b, err := msgp.ReadMapHeaderBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]mapEntry, 0, size)
+	for range size {
+		var key, value msgp.Raw
+		key, b, err = readRaw(b)
+		if err != nil {
+			return nil, err
+		}
+		value, b, err = readRaw(b)
+		if err != nil {
+			return nil, err
+		}
+		id := "raw:" + string(key)
+		if str, _, err := msgp.ReadStringBytes(key); err == nil {
+			id = "str:" + str
+		}
+		entries = append(entries, mapEntry{id: id, key: key, value: value})
+	}
+	return entries, nil
+}
+
+// readRaw returns the next encoded object and the remaining bytes
+func readRaw(b []byte) (msgp.Raw, []byte, error) {
+	rest, err := msgp.Skip(b)
+	if err != nil {
+		return nil, b, err
+	}
+	return msgp.Raw(b[:len(b)-len(rest)]), rest, nil
+}
+
+func writeMap(entries []mapEntry) Content {
+	b := msgp.AppendMapHeader(nil, uint32(len(entries)))
+	for _, entry := range entries {
+		b = append(b, entry.key...)
+		b = append(b, entry.value...)
+	}
+	return Content(b)
+}
@@ -0,0 +1,41 @@
+package resource_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ProjectLighthouseCAU/beacon/resource"
+	"github.com/tinylib/msgp/msgp"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name                   string
+		value, patch, expected any
+	}{
+		{"add and replace", map[string]any{"a": int64(1), "b": int64(2)}, map[string]any{"b": int64(3), "c": int64(4)}, map[string]any{"a": int64(1), "b": int64(3), "c": int64(4)}},
+		{"delete", map[string]any{"a": int64(1), "b": int64(2)}, map[string]any{"a": nil, "x": nil}, map[string]any{"b": int64(2)}},
+		{"nested", map[string]any{"a": map[string]any{"x": int64(1), "y": int64(2)}}, map[string]any{"a": map[string]any{"y": nil, "z": int64(3)}}, map[string]any{"a": map[string]any{"x": int64(1), "z": int64(3)}}},
+		{"arrays are replaced", map[string]any{"a": []any{int64(1), int64(2)}}, map[string]any{"a": []any{int64(3)}}, map[string]any{"a": []any{int64(3)}}},
+		{"no map", []any{int64(1)}, map[string]any{"a": int64(1), "b": nil}, map[string]any{"a": int64(1)}},
+	}
+	for _, test := range tests {
+		value, _ := msgp.AppendIntf(nil, test.value)
+		patch, _ := msgp.AppendIntf(nil, test.patch)
+		merged, err := resource.Merge(value, patch)
+		if err != nil {
+			t.Fatalf("%s: Merge failed: %s", test.name, err)
+		}
+		got, _, err := msgp.ReadIntfBytes(merged)
+		if err != nil {
+			t.Fatalf("%s: merged value is invalid: %s", test.name, err)
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Fatalf("%s: Merge expected %v, but got %v", test.name, test.expected, got)
+		}
+	}
+
+	if _, err := resource.Merge(resource.Nil, msgp.AppendInt(nil, 1)); err != resource.ErrPatchNotMap {
+		t.Fatalf("Merge with a patch that is not a map expected %s, but got %v", resource.ErrPatchNotMap, err)
+	}
+}
@@ -14,12 +14,19 @@ type Content msgp.Raw
 var Nil Content = msgp.AppendNil(msgp.Raw{}) // msgp.AppendInt8(msgp.Raw{}, 0) // msgp.AppendNil(msgp.Raw{}) // note: msgp.Raw with encoded nil is decoded as empty []byte
 
 // Generic definition of a resource that implements storage and retrieval of a generic value (Put, Get) as well as the publish-subscribe mechanism (Stream, StopStream)
-// and linking other resources (Link, Unlink) as well as a destructor/deinitialization-function (Close)
+// and linking other resources (Link, Unlink) as well as a destructor/deinitialization-function (Close).
+// Every value has a version that starts at 0 when the resource is created and increases with every Put,
+// which allows for compare-and-swap updates (PutVersioned).
 type Resource[T any] interface {
 	Stream() chan T
 	StopStream(chan T) error
 	Put(T) error
+	// PutVersioned updates the value like Put, but only if the current version equals ifVersion (unless it is nil).
+	// Returns the new version, or the current version and ErrVersionMismatch if the version does not match.
+	PutVersioned(value T, ifVersion *uint64) (uint64, error)
 	Get() T
+	// GetVersioned returns the current value together with its version
+	GetVersioned() (T, uint64)
 	Link(Resource[T]) error
 	UnLink(Resource[T]) error
 	Close()
@@ -34,6 +41,8 @@ var (
 	ErrLinkNotFound   = errors.New("link does not exist")
 	// 409
 	ErrLinkLoop = errors.New("link causes a loop")
+	// 412
+	ErrVersionMismatch = errors.New("version does not match")
 	// 500
 	ErrWrongResourceImpl = errors.New("link resource must be of the same type as this resource")
 )
@@ -58,6 +67,10 @@ func ErrorToStatusCode(err error) int {
 	case ErrLinkLoop:
 		return http.StatusConflict
 
+	// 412 Precondition Failed
+	case ErrVersionMismatch:
+		return http.StatusPreconditionFailed
+
 	// 500 Internal Server Error
 	case ErrWrongResourceImpl:
 		return http.StatusInternalServerError
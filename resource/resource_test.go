@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ProjectLighthouseCAU/beacon/resource"
+
 	// TODO: test all implementations of a resource
 
 	resourceImpl "github.com/ProjectLighthouseCAU/beacon/resource/brokerless" // <- change resource implementation here
@@ -37,6 +39,31 @@ func TestPut(t *testing.T) {
 	testResource.Close()
 }
 
+func TestPutVersioned(t *testing.T) {
+	testResource := resourceImpl.Create[any]([]string{}, nil)
+	if _, version := testResource.GetVersioned(); version != 0 {
+		t.Fatalf("New resource expected version 0, but got %d", version)
+	}
+	ifVersion := uint64(0)
+	version, err := testResource.PutVersioned(expected, &ifVersion)
+	if err != nil || version != 1 {
+		t.Fatalf("PutVersioned with matching version expected version 1, but got %d (error: %v)", version, err)
+	}
+	version, err = testResource.PutVersioned(expected2, &ifVersion) // outdated version
+	if err != resource.ErrVersionMismatch || version != 1 {
+		t.Fatalf("PutVersioned with outdated version expected %s and version 1, but got %v and version %d", resource.ErrVersionMismatch, err, version)
+	}
+	got, version := testResource.GetVersioned()
+	if got != expected || version != 1 {
+		t.Fatalf("GetVersioned expected %s (version 1), but got %s (version %d)", expected, got, version)
+	}
+	testResource.Put(expected2) // unconditional
+	if _, version := testResource.GetVersioned(); version != 2 {
+		t.Fatalf("Put expected version 2, but got %d", version)
+	}
+	testResource.Close()
+}
+
 func TestStream(t *testing.T) {
 	testResource := resourceImpl.Create[any]([]string{}, nil)
 	stream := testResource.Stream()
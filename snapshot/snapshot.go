@@ -1,6 +1,7 @@
 package snapshot
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -102,34 +103,62 @@ func restore(reader io.ReadSeeker, dir directory.Directory[resource.Resource[res
 	if len(snapshotMsgpack) == 0 {
 		return nil
 	}
-	var snapshot types.Snapshot
-	bs, err := snapshot.UnmarshalMsg(snapshotMsgpack)
-	if err != nil || len(bs) > 0 {
+	snapshot, err := decodeSnapshot(snapshotMsgpack)
+	if err != nil {
 		return err
 	}
 
 	newDir := tree.NewTree[resource.Resource[resource.Content]]()
-	for pathStr, value := range snapshot {
+	for pathStr, entry := range snapshot.Resources {
 		path := strings.Split(pathStr, "/")
-		content := (resource.Content)(value)
+		content := (resource.Content)(entry.Value)
 		// special case: msgpack.Nil is decoded as empty array
 		// empty arrays are decoded as [0x90] (msgpack array header with length 0)
 		if len(content) == 0 {
 			content = resource.Nil
 		}
-		err := newDir.CreateLeaf(path, brokerless.Create(path, content))
+		// restored resources keep their version, so that versions of previous contents are not reused
+		err := newDir.CreateLeaf(path, brokerless.CreateVersioned(path, content, entry.Version))
 		if err != nil {
-			return fmt.Errorf("[ERROR snapshot.restore] cannot restore path: %v with value %v: %w", path, value, err)
+			return fmt.Errorf("[ERROR snapshot.restore] cannot restore path: %v with value %v: %w", path, entry.Value, err)
 		}
 	}
 	// successfully read snapshot into newDir -> delete dir and load snapshot
-	dir.ForEach([]string{}, func(path []string, resource resource.Resource[resource.Content]) (bool, error) {
+	dir.ForEach(context.Background(), []string{}, func(path []string, resource resource.Resource[resource.Content]) (bool, error) {
 		resource.Close()
 		return true, nil
 	})
 	return dir.ChRoot(newDir)
 }
 
+// decodeSnapshot decodes a snapshot file with a format or a snapshot file of an older version (without versions)
+func decodeSnapshot(snapshotMsgpack []byte) (*types.SnapshotFile, error) {
+	var file types.SnapshotFile
+	bs, err := file.UnmarshalMsg(snapshotMsgpack)
+	if err == nil && file.Format > 0 {
+		if file.Format > types.SnapshotFormat {
+			return nil, fmt.Errorf("[ERROR snapshot.restore] unsupported snapshot format %d", file.Format)
+		}
+		if len(bs) > 0 {
+			return nil, fmt.Errorf("[ERROR snapshot.restore] %d unexpected bytes after the snapshot", len(bs))
+		}
+		return &file, nil
+	}
+	var snapshot types.Snapshot
+	bs, err = snapshot.UnmarshalMsg(snapshotMsgpack)
+	if err != nil {
+		return nil, err
+	}
+	if len(bs) > 0 {
+		return nil, fmt.Errorf("[ERROR snapshot.restore] %d unexpected bytes after the snapshot", len(bs))
+	}
+	file = types.SnapshotFile{Resources: make(map[string]types.SnapshotResource, len(snapshot))}
+	for pathStr, value := range snapshot {
+		file.Resources[pathStr] = types.SnapshotResource{Value: value}
+	}
+	return &file, nil
+}
+
 func Snapshot(snapshotFilePath string, dir directory.Directory[resource.Resource[resource.Content]]) error {
 	file, err := openOrCreateFile(snapshotFilePath)
 	if err != nil {
@@ -151,12 +180,13 @@ func snapshot(writer io.WriteSeeker, dir directory.Directory[resource.Resource[r
 		truncater.Truncate(0)
 	}
 	writer.Seek(0, io.SeekStart)
-	snapshot := types.NewSnapshot()
-	if err := dir.ForEach([]string{}, func(path []string, value resource.Resource[resource.Content]) (bool, error) {
+	snapshot := types.SnapshotFile{Format: types.SnapshotFormat, Resources: make(map[string]types.SnapshotResource)}
+	if err := dir.ForEach(context.Background(), []string{}, func(path []string, value resource.Resource[resource.Content]) (bool, error) {
 		// key (path as string)
 		// TODO: using []string does not work properly for unmarshaling, since go does not allow slices as map keys
 		pathStr := strings.Join(path, "/") // we ensure in handler.go that paths do not contain "/"
-		snapshot[pathStr] = (msgp.Raw)(value.Get())
+		content, version := value.GetVersioned()
+		snapshot.Resources[pathStr] = types.SnapshotResource{Value: (msgp.Raw)(content), Version: version}
 		return true, nil
 	}); err != nil {
 		return err
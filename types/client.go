@@ -3,10 +3,10 @@ package types
 import (
 	"context"
 	"log"
+	"slices"
 	"sync"
 	"time"
 
-	"github.com/ProjectLighthouseCAU/beacon/directory"
 	"github.com/ProjectLighthouseCAU/beacon/resource"
 	"github.com/tinylib/msgp/msgp"
 )
@@ -14,9 +14,19 @@ import (
 // The Client type stores a Send function via which the server can send a Response to the client
 // as well as a Streams map that stores the active stream channels for each resource path.
 type Client struct {
-	Send    func(*Response) error
-	ip      string
-	streams map[reid]map[path]chan resource.Content
+	Send        func(*Response) error
+	ip          string
+	certUser    string              // username of the verified TLS client certificate
+	authorize   func(*Request) bool // auth of the endpoint for requests on behalf of the client (nil: all allowed)
+	session     string              // ID of the session that can be resumed after reconnecting ("" if none)
+	sessionLock sync.RWMutex
+	streams     map[reid]map[path]*stream
+	watches     map[reid]map[path]func() // stop functions of the active watches
+	streamsLock sync.Mutex
+
+	protocolVersion int             // declared with a HELLO request (0 if not declared)
+	features        map[string]bool // enabled with a HELLO request
+	featuresLock    sync.RWMutex
 
 	authCache                   map[string]*AuthCacheEntry
 	authCacheLock               sync.RWMutex
@@ -26,6 +36,28 @@ type Client struct {
 type reid string // using REID (msgp.Raw) which is a []byte converted to string as map key
 type path string // using PATH ([]string) converted to msgpack []byte converted to string as map key
 
+// stream of a client with the streamed resource (which might have been moved to another path in the meantime)
+type stream struct {
+	channel  chan resource.Content
+	resource resource.Resource[resource.Content]
+	options  Meta // options of the STREAM request (e.g. MAX_RATE)
+}
+
+// Subscription identifies a stream of a client by the REID and PATH of the STREAM request
+type Subscription struct {
+	REID     msgp.Raw
+	PATH     []string
+	META     Meta                                // options of the STREAM request
+	Resource resource.Resource[resource.Content] // streamed resource (which might have been moved away from PATH)
+}
+
+// Features that a client can enable for its connection with a HELLO request
+const (
+	FeatureNoResponse = "NORESPONSE" // no responses unless a request sets META NORESPONSE to false
+	FeatureNoAck      = "NOACK"      // no responses to successful writes unless a request sets META NOACK to false
+	FeatureJSON       = "JSON"       // JSON instead of MessagePack (only supported by some endpoints)
+)
+
 type AuthCacheEntry struct {
 	Token     string
 	ExpiresAt time.Time
@@ -37,7 +69,9 @@ func NewClient(ip string, send func(*Response) error) *Client {
 	return &Client{
 		Send:                        send,
 		ip:                          ip,
-		streams:                     make(map[reid]map[path]chan resource.Content),
+		streams:                     make(map[reid]map[path]*stream),
+		watches:                     make(map[reid]map[path]func()),
+		features:                    make(map[string]bool),
 		authCache:                   make(map[string]*AuthCacheEntry),
 		authCacheUpdaterCancelFuncs: make(map[string]context.CancelFunc),
 	}
@@ -47,6 +81,97 @@ func (c *Client) Ip() string {
 	return c.ip
 }
 
+// CertificateUser returns the username that the client authenticated as with a TLS client certificate ("" if none)
+func (c *Client) CertificateUser() string {
+	return c.certUser
+}
+
+func (c *Client) SetCertificateUser(username string) {
+	c.certUser = username
+}
+
+// SetAuthorizer sets the auth of the endpoint for checking requests on behalf of the client
+// that the client did not send itself (e.g. reading the paths in the events of a watch)
+func (c *Client) SetAuthorizer(authorize func(*Request) bool) {
+	c.authorize = authorize
+}
+
+// Authorized reports whether the client is authorized for a request on its behalf (true if no authorizer is set)
+func (c *Client) Authorized(request *Request) bool {
+	return c.authorize == nil || c.authorize(request)
+}
+
+// Session returns the ID of the session of the client ("" if session resumption is disabled)
+func (c *Client) Session() string {
+	c.sessionLock.RLock()
+	defer c.sessionLock.RUnlock()
+	return c.session
+}
+
+func (c *Client) SetSession(session string) {
+	c.sessionLock.Lock()
+	defer c.sessionLock.Unlock()
+	c.session = session
+}
+
+// protocol version and features
+
+func (c *Client) ProtocolVersion() int {
+	c.featuresLock.RLock()
+	defer c.featuresLock.RUnlock()
+	return c.protocolVersion
+}
+
+func (c *Client) SetProtocolVersion(version int) {
+	c.featuresLock.Lock()
+	defer c.featuresLock.Unlock()
+	c.protocolVersion = version
+}
+
+func (c *Client) HasFeature(feature string) bool {
+	c.featuresLock.RLock()
+	defer c.featuresLock.RUnlock()
+	return c.features[feature]
+}
+
+func (c *Client) SetFeature(feature string, enabled bool) {
+	c.featuresLock.Lock()
+	defer c.featuresLock.Unlock()
+	c.features[feature] = enabled
+}
+
+// Features returns the enabled features of the client
+func (c *Client) Features() []string {
+	c.featuresLock.RLock()
+	defer c.featuresLock.RUnlock()
+	features := make([]string, 0, len(c.features))
+	for feature, enabled := range c.features {
+		if enabled {
+			features = append(features, feature)
+		}
+	}
+	slices.Sort(features)
+	return features
+}
+
+// NoResponse reports whether the client does not want a response to the request
+// (META NORESPONSE of the request, or the NORESPONSE feature of the client if the request does not set it)
+func (c *Client) NoResponse(request *Request) bool {
+	if noResponse, ok := request.META["NORESPONSE"].(bool); ok {
+		return noResponse
+	}
+	return c.HasFeature(FeatureNoResponse)
+}
+
+// NoAck reports whether the client only wants a response to the request if it fails
+// (META NOACK of the request, or the NOACK feature of the client if the request does not set it)
+func (c *Client) NoAck(request *Request) bool {
+	if noAck, ok := request.META["NOACK"].(bool); ok {
+		return noAck
+	}
+	return c.HasFeature(FeatureNoAck)
+}
+
 // helpers
 
 func reidToMapKey(REID msgp.Raw) reid {
@@ -72,28 +197,36 @@ func pathFromMapKey(p path) []string {
 
 // streams
 
-func (c *Client) AddStream(REID msgp.Raw, PATH []string, stream chan resource.Content) {
+func (c *Client) AddStream(REID msgp.Raw, PATH []string, channel chan resource.Content, resrc resource.Resource[resource.Content], options Meta) {
+	c.streamsLock.Lock()
+	defer c.streamsLock.Unlock()
 	reidKey := reidToMapKey(REID)
 	_, ok := c.streams[reidKey]
 	if !ok {
-		c.streams[reidKey] = make(map[path]chan resource.Content)
+		c.streams[reidKey] = make(map[path]*stream)
 	}
-	c.streams[reidKey][pathToMapKey(PATH)] = stream
+	c.streams[reidKey][pathToMapKey(PATH)] = &stream{channel, resrc, options}
 }
 
-func (c *Client) GetStream(reid msgp.Raw, path []string) chan resource.Content {
+// GetStream returns the stream channel with the REID and PATH of the STREAM request and the streamed resource
+// (nil if there is no such stream)
+func (c *Client) GetStream(reid msgp.Raw, path []string) (chan resource.Content, resource.Resource[resource.Content]) {
+	c.streamsLock.Lock()
+	defer c.streamsLock.Unlock()
 	streams, ok := c.streams[reidToMapKey(reid)]
 	if !ok {
-		return nil
+		return nil, nil
 	}
 	stream, ok := streams[pathToMapKey(path)]
 	if !ok {
-		return nil
+		return nil, nil
 	}
-	return stream
+	return stream.channel, stream.resource
 }
 
 func (c *Client) RemoveStream(reid msgp.Raw, path []string) {
+	c.streamsLock.Lock()
+	defer c.streamsLock.Unlock()
 	reidKey := reidToMapKey(reid)
 	streams, ok := c.streams[reidKey]
 	if !ok {
@@ -110,6 +243,67 @@ func (c *Client) RemoveStream(reid msgp.Raw, path []string) {
 	}
 }
 
+// HasStreams reports whether the client has active streams or watches
+func (c *Client) HasStreams() bool {
+	c.streamsLock.Lock()
+	defer c.streamsLock.Unlock()
+	return len(c.streams) > 0 || len(c.watches) > 0
+}
+
+// Subscriptions returns the REIDs, PATHs, options and resources of all active streams
+func (c *Client) Subscriptions() []Subscription {
+	c.streamsLock.Lock()
+	defer c.streamsLock.Unlock()
+	subscriptions := make([]Subscription, 0, len(c.streams))
+	for reid, streams := range c.streams {
+		for path, stream := range streams {
+			subscriptions = append(subscriptions, Subscription{REID: msgp.Raw(reid), PATH: pathFromMapKey(path), META: stream.options, Resource: stream.resource})
+		}
+	}
+	return subscriptions
+}
+
+// watches
+
+// AddWatch adds a watch with the REID and PATH of the WATCH request and the function that stops it.
+// Returns false if there already is such a watch.
+func (c *Client) AddWatch(REID msgp.Raw, PATH []string, stop func()) bool {
+	c.streamsLock.Lock()
+	defer c.streamsLock.Unlock()
+	reidKey := reidToMapKey(REID)
+	watches, ok := c.watches[reidKey]
+	if !ok {
+		watches = make(map[path]func())
+		c.watches[reidKey] = watches
+	}
+	pathKey := pathToMapKey(PATH)
+	if _, ok := watches[pathKey]; ok {
+		return false
+	}
+	watches[pathKey] = stop
+	return true
+}
+
+// StopWatch stops and removes the watch with the REID and PATH of the WATCH request.
+// Returns false if there is no such watch.
+func (c *Client) StopWatch(REID msgp.Raw, PATH []string) bool {
+	c.streamsLock.Lock()
+	reidKey := reidToMapKey(REID)
+	pathKey := pathToMapKey(PATH)
+	stop, ok := c.watches[reidKey][pathKey]
+	if ok {
+		delete(c.watches[reidKey], pathKey)
+		if len(c.watches[reidKey]) == 0 {
+			delete(c.watches, reidKey)
+		}
+	}
+	c.streamsLock.Unlock()
+	if ok {
+		stop()
+	}
+	return ok
+}
+
 // auth cache
 
 func (c *Client) IsAuthCacheEmpty() bool {
@@ -148,17 +342,22 @@ func (c *Client) RemoveAuthCacheUpdaterCancelFunc(username string) {
 	c.authCacheLock.Unlock()
 }
 
-func (c *Client) Disconnect(dir directory.Directory[resource.Resource[resource.Content]]) {
+func (c *Client) Disconnect() {
 	// Stop all streams of this client
+	c.streamsLock.Lock()
 	for _, streams := range c.streams {
-		for path, stream := range streams {
-			resource, err := dir.GetLeaf(pathFromMapKey(path))
-			if err != nil {
-				continue
-			}
-			_ = resource.StopStream(stream)
+		for _, stream := range streams {
+			_ = stream.resource.StopStream(stream.channel)
+		}
+	}
+	// Stop all watches of this client
+	for _, watches := range c.watches {
+		for _, stop := range watches {
+			stop()
 		}
 	}
+	c.watches = make(map[reid]map[path]func())
+	c.streamsLock.Unlock()
 	// Stop all cache updaters of this client
 	c.authCacheLock.Lock()
 	for _, cancel := range c.authCacheUpdaterCancelFuncs {
@@ -0,0 +1,78 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// JSONToMsgpack converts a JSON document into the equivalent MessagePack encoding.
+// Integral numbers are encoded as MessagePack integers, all other numbers as floats.
+func JSONToMsgpack(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return appendJSONValue(nil, value)
+}
+
+func appendJSONValue(b []byte, value any) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return msgp.AppendNil(b), nil
+	case bool:
+		return msgp.AppendBool(b, v), nil
+	case string:
+		return msgp.AppendString(b, v), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return msgp.AppendInt64(b, i), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return msgp.AppendFloat64(b, f), nil
+	case []any:
+		b = msgp.AppendArrayHeader(b, uint32(len(v)))
+		for _, elem := range v {
+			var err error
+			b, err = appendJSONValue(b, elem)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]any:
+		b = msgp.AppendMapHeader(b, uint32(len(v)))
+		for key, elem := range v {
+			b = msgp.AppendString(b, key)
+			var err error
+			b, err = appendJSONValue(b, elem)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unexpected JSON type %T", value)
+	}
+}
+
+// MsgpackToJSON converts a MessagePack encoded value into JSON (binary data is encoded as base64 string)
+func MsgpackToJSON(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := msgp.UnmarshalAsJSON(&buf, data)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
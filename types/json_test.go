@@ -0,0 +1,48 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+func TestJSONToMsgpack(t *testing.T) {
+	bts, err := JSONToMsgpack([]byte(`[1, -2, 2.5, "x", true, null, {"a": {}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, left, err := msgp.ReadIntfBytes(bts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) > 0 {
+		t.Errorf("%d bytes left over after ReadIntfBytes()", len(left))
+	}
+	arr := v.([]any)
+	if arr[0] != int64(1) || arr[1] != int64(-2) || arr[2] != 2.5 || arr[3] != "x" || arr[4] != true || arr[5] != nil {
+		t.Fatalf("unexpected conversion result %v", arr)
+	}
+	if _, ok := arr[6].(map[string]any)["a"].(map[string]any); !ok {
+		t.Fatalf("expected nested map, but got %v", arr[6])
+	}
+}
+
+func TestJSONToMsgpackRejectsTrailingData(t *testing.T) {
+	_, err := JSONToMsgpack([]byte(`1 2`))
+	if err == nil {
+		t.Fatal("expected error for trailing data")
+	}
+}
+
+func TestMsgpackToJSON(t *testing.T) {
+	bts := msgp.AppendMapHeader(nil, 1)
+	bts = msgp.AppendString(bts, "a")
+	bts = msgp.AppendInt(bts, 1)
+	json, err := MsgpackToJSON(bts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(json) != `{"a":1}` {
+		t.Fatalf("expected {\"a\":1}, but got %s", json)
+	}
+}
@@ -0,0 +1,127 @@
+package types
+
+import (
+	"github.com/tinylib/msgp/msgp"
+)
+
+// Meta is the type of the META field of requests and responses.
+// The Lighthouse-Protocol allows any MessagePack type as key and value (Map<*,*>),
+// which msgp cannot generate code for, so the (de)serialization is implemented by hand.
+// Keys that cannot be used as a Go map key (maps, arrays and binary) are skipped while decoding.
+type Meta map[any]any
+
+var (
+	_ msgp.Decodable   = (*Meta)(nil)
+	_ msgp.Encodable   = (*Meta)(nil)
+	_ msgp.Marshaler   = (*Meta)(nil)
+	_ msgp.Unmarshaler = (*Meta)(nil)
+	_ msgp.Sizer       = (*Meta)(nil)
+)
+
+func isValidMetaKey(key any) bool {
+	switch key.(type) {
+	case map[string]any, []any, []byte:
+		return false
+	}
+	return true
+}
+
+// DecodeMsg implements msgp.Decodable
+func (m *Meta) DecodeMsg(dc *msgp.Reader) error {
+	if dc.IsNil() {
+		*m = nil
+		return dc.ReadNil()
+	}
+	sz, err := dc.ReadMapHeader()
+	if err != nil {
+		return msgp.WrapError(err, "META")
+	}
+	*m = make(Meta, sz)
+	for range sz {
+		key, err := dc.ReadIntf()
+		if err != nil {
+			return msgp.WrapError(err, "META")
+		}
+		value, err := dc.ReadIntf()
+		if err != nil {
+			return msgp.WrapError(err, "META", key)
+		}
+		if isValidMetaKey(key) {
+			(*m)[key] = value
+		}
+	}
+	return nil
+}
+
+// EncodeMsg implements msgp.Encodable
+func (m Meta) EncodeMsg(en *msgp.Writer) error {
+	err := en.WriteMapHeader(uint32(len(m)))
+	if err != nil {
+		return msgp.WrapError(err, "META")
+	}
+	for key, value := range m {
+		err = en.WriteIntf(key)
+		if err != nil {
+			return msgp.WrapError(err, "META")
+		}
+		err = en.WriteIntf(value)
+		if err != nil {
+			return msgp.WrapError(err, "META", key)
+		}
+	}
+	return nil
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (m Meta) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, m.Msgsize())
+	o = msgp.AppendMapHeader(o, uint32(len(m)))
+	for key, value := range m {
+		o, err = msgp.AppendIntf(o, key)
+		if err != nil {
+			return o, msgp.WrapError(err, "META")
+		}
+		o, err = msgp.AppendIntf(o, value)
+		if err != nil {
+			return o, msgp.WrapError(err, "META", key)
+		}
+	}
+	return o, nil
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (m *Meta) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	if msgp.IsNil(bts) {
+		*m = nil
+		return msgp.ReadNilBytes(bts)
+	}
+	sz, bts, err := msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		return bts, msgp.WrapError(err, "META")
+	}
+	*m = make(Meta, sz)
+	for range sz {
+		var key, value any
+		key, bts, err = msgp.ReadIntfBytes(bts)
+		if err != nil {
+			return bts, msgp.WrapError(err, "META")
+		}
+		value, bts, err = msgp.ReadIntfBytes(bts)
+		if err != nil {
+			return bts, msgp.WrapError(err, "META", key)
+		}
+		if isValidMetaKey(key) {
+			(*m)[key] = value
+		}
+	}
+	return bts, nil
+}
+
+// Msgsize implements msgp.Sizer (upper bound estimate)
+func (m Meta) Msgsize() int {
+	s := msgp.MapHeaderSize
+	for key, value := range m {
+		s += msgp.GuessSize(key) + msgp.GuessSize(value)
+	}
+	return s
+}
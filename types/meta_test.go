@@ -0,0 +1,49 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+func TestRequestMetaRoundTrip(t *testing.T) {
+	req := Request{
+		REID: msgp.AppendInt(nil, 1),
+		VERB: "LIST",
+		META: Meta{"NONRECURSIVE": true, int64(1): "one"},
+	}
+	bts, err := req.MarshalMsg(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Request
+	_, err = got.UnmarshalMsg(bts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonrecursive, ok := got.META["NONRECURSIVE"].(bool); !ok || !nonrecursive {
+		t.Fatalf("META[NONRECURSIVE] expected true, but got %v", got.META["NONRECURSIVE"])
+	}
+	if got.META[int64(1)] != "one" {
+		t.Fatalf("META[1] expected \"one\", but got %v", got.META[int64(1)])
+	}
+}
+
+func TestMetaSkipsUnhashableKeys(t *testing.T) {
+	bts := msgp.AppendMapHeader(nil, 2)
+	bts = msgp.AppendArrayHeader(bts, 0) // [] -> cannot be a map key
+	bts = msgp.AppendBool(bts, true)
+	bts = msgp.AppendString(bts, "KEY")
+	bts = msgp.AppendBool(bts, true)
+	var meta Meta
+	left, err := meta.UnmarshalMsg(bts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) > 0 {
+		t.Errorf("%d bytes left over after UnmarshalMsg()", len(left))
+	}
+	if len(meta) != 1 || meta["KEY"] != true {
+		t.Fatalf("expected only KEY to be decoded, but got %v", meta)
+	}
+}
@@ -15,7 +15,7 @@ type Request struct {
 	AUTH map[string]string
 	VERB string
 	PATH []string
-	META map[any]any
+	META Meta
 	PAYL msgp.Raw
 }
 
@@ -1,7 +1,7 @@
-package types
-
 // Code generated by github.com/tinylib/msgp DO NOT EDIT.
 
+package types
+
 import (
 	"github.com/tinylib/msgp/msgp"
 )
@@ -40,19 +40,17 @@ func (z *Request) DecodeMsg(dc *msgp.Reader) (err error) {
 			if z.AUTH == nil {
 				z.AUTH = make(map[string]string, zb0002)
 			} else if len(z.AUTH) > 0 {
-				for key := range z.AUTH {
-					delete(z.AUTH, key)
-				}
+				clear(z.AUTH)
 			}
 			for zb0002 > 0 {
 				zb0002--
 				var za0001 string
-				var za0002 string
 				za0001, err = dc.ReadString()
 				if err != nil {
 					err = msgp.WrapError(err, "AUTH")
 					return
 				}
+				var za0002 string
 				za0002, err = dc.ReadString()
 				if err != nil {
 					err = msgp.WrapError(err, "AUTH", za0001)
@@ -85,6 +83,12 @@ func (z *Request) DecodeMsg(dc *msgp.Reader) (err error) {
 					return
 				}
 			}
+		case "META":
+			err = z.META.DecodeMsg(dc)
+			if err != nil {
+				err = msgp.WrapError(err, "META")
+				return
+			}
 		case "PAYL":
 			err = z.PAYL.DecodeMsg(dc)
 			if err != nil {
@@ -104,9 +108,9 @@ func (z *Request) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *Request) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 5
+	// map header, size 6
 	// write "REID"
-	err = en.Append(0x85, 0xa4, 0x52, 0x45, 0x49, 0x44)
+	err = en.Append(0x86, 0xa4, 0x52, 0x45, 0x49, 0x44)
 	if err != nil {
 		return
 	}
@@ -164,6 +168,16 @@ func (z *Request) EncodeMsg(en *msgp.Writer) (err error) {
 			return
 		}
 	}
+	// write "META"
+	err = en.Append(0xa4, 0x4d, 0x45, 0x54, 0x41)
+	if err != nil {
+		return
+	}
+	err = z.META.EncodeMsg(en)
+	if err != nil {
+		err = msgp.WrapError(err, "META")
+		return
+	}
 	// write "PAYL"
 	err = en.Append(0xa4, 0x50, 0x41, 0x59, 0x4c)
 	if err != nil {
@@ -180,9 +194,9 @@ func (z *Request) EncodeMsg(en *msgp.Writer) (err error) {
 // MarshalMsg implements msgp.Marshaler
 func (z *Request) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 5
+	// map header, size 6
 	// string "REID"
-	o = append(o, 0x85, 0xa4, 0x52, 0x45, 0x49, 0x44)
+	o = append(o, 0x86, 0xa4, 0x52, 0x45, 0x49, 0x44)
 	o, err = z.REID.MarshalMsg(o)
 	if err != nil {
 		err = msgp.WrapError(err, "REID")
@@ -204,6 +218,13 @@ func (z *Request) MarshalMsg(b []byte) (o []byte, err error) {
 	for za0003 := range z.PATH {
 		o = msgp.AppendString(o, z.PATH[za0003])
 	}
+	// string "META"
+	o = append(o, 0xa4, 0x4d, 0x45, 0x54, 0x41)
+	o, err = z.META.MarshalMsg(o)
+	if err != nil {
+		err = msgp.WrapError(err, "META")
+		return
+	}
 	// string "PAYL"
 	o = append(o, 0xa4, 0x50, 0x41, 0x59, 0x4c)
 	o, err = z.PAYL.MarshalMsg(o)
@@ -248,14 +269,12 @@ func (z *Request) UnmarshalMsg(bts []byte) (o []byte, err error) {
 			if z.AUTH == nil {
 				z.AUTH = make(map[string]string, zb0002)
 			} else if len(z.AUTH) > 0 {
-				for key := range z.AUTH {
-					delete(z.AUTH, key)
-				}
+				clear(z.AUTH)
 			}
 			for zb0002 > 0 {
-				var za0001 string
 				var za0002 string
 				zb0002--
+				var za0001 string
 				za0001, bts, err = msgp.ReadStringBytes(bts)
 				if err != nil {
 					err = msgp.WrapError(err, "AUTH")
@@ -293,6 +312,12 @@ func (z *Request) UnmarshalMsg(bts []byte) (o []byte, err error) {
 					return
 				}
 			}
+		case "META":
+			bts, err = z.META.UnmarshalMsg(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "META")
+				return
+			}
 		case "PAYL":
 			bts, err = z.PAYL.UnmarshalMsg(bts)
 			if err != nil {
@@ -324,6 +349,6 @@ func (z *Request) Msgsize() (s int) {
 	for za0003 := range z.PATH {
 		s += msgp.StringPrefixSize + len(z.PATH[za0003])
 	}
-	s += 5 + z.PAYL.Msgsize()
+	s += 5 + z.META.Msgsize() + 5 + z.PAYL.Msgsize()
 	return
 }
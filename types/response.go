@@ -15,14 +15,14 @@ type Response struct {
 	REID     msgp.Raw
 	RNUM     int
 	RESPONSE string
-	META     map[any]any
+	META     Meta
 	PAYL     msgp.Raw
 	WARNINGS []string
 }
 
 func NewResponse() *Response {
 	return &Response{
-		META:     map[any]any{},
+		META:     Meta{},
 		WARNINGS: []string{},
 	}
 }
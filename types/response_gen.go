@@ -1,7 +1,7 @@
-package types
-
 // Code generated by github.com/tinylib/msgp DO NOT EDIT.
 
+package types
+
 import (
 	"github.com/tinylib/msgp/msgp"
 )
@@ -42,6 +42,12 @@ func (z *Response) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "RESPONSE")
 				return
 			}
+		case "META":
+			err = z.META.DecodeMsg(dc)
+			if err != nil {
+				err = msgp.WrapError(err, "META")
+				return
+			}
 		case "PAYL":
 			err = z.PAYL.DecodeMsg(dc)
 			if err != nil {
@@ -80,9 +86,9 @@ func (z *Response) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *Response) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 5
+	// map header, size 6
 	// write "REID"
-	err = en.Append(0x85, 0xa4, 0x52, 0x45, 0x49, 0x44)
+	err = en.Append(0x86, 0xa4, 0x52, 0x45, 0x49, 0x44)
 	if err != nil {
 		return
 	}
@@ -111,6 +117,16 @@ func (z *Response) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "RESPONSE")
 		return
 	}
+	// write "META"
+	err = en.Append(0xa4, 0x4d, 0x45, 0x54, 0x41)
+	if err != nil {
+		return
+	}
+	err = z.META.EncodeMsg(en)
+	if err != nil {
+		err = msgp.WrapError(err, "META")
+		return
+	}
 	// write "PAYL"
 	err = en.Append(0xa4, 0x50, 0x41, 0x59, 0x4c)
 	if err != nil {
@@ -144,9 +160,9 @@ func (z *Response) EncodeMsg(en *msgp.Writer) (err error) {
 // MarshalMsg implements msgp.Marshaler
 func (z *Response) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 5
+	// map header, size 6
 	// string "REID"
-	o = append(o, 0x85, 0xa4, 0x52, 0x45, 0x49, 0x44)
+	o = append(o, 0x86, 0xa4, 0x52, 0x45, 0x49, 0x44)
 	o, err = z.REID.MarshalMsg(o)
 	if err != nil {
 		err = msgp.WrapError(err, "REID")
@@ -158,6 +174,13 @@ func (z *Response) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "RESPONSE"
 	o = append(o, 0xa8, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45)
 	o = msgp.AppendString(o, z.RESPONSE)
+	// string "META"
+	o = append(o, 0xa4, 0x4d, 0x45, 0x54, 0x41)
+	o, err = z.META.MarshalMsg(o)
+	if err != nil {
+		err = msgp.WrapError(err, "META")
+		return
+	}
 	// string "PAYL"
 	o = append(o, 0xa4, 0x50, 0x41, 0x59, 0x4c)
 	o, err = z.PAYL.MarshalMsg(o)
@@ -210,6 +233,12 @@ func (z *Response) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "RESPONSE")
 				return
 			}
+		case "META":
+			bts, err = z.META.UnmarshalMsg(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "META")
+				return
+			}
 		case "PAYL":
 			bts, err = z.PAYL.UnmarshalMsg(bts)
 			if err != nil {
@@ -249,7 +278,7 @@ func (z *Response) UnmarshalMsg(bts []byte) (o []byte, err error) {
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z *Response) Msgsize() (s int) {
-	s = 1 + 5 + z.REID.Msgsize() + 5 + msgp.IntSize + 9 + msgp.StringPrefixSize + len(z.RESPONSE) + 5 + z.PAYL.Msgsize() + 9 + msgp.ArrayHeaderSize
+	s = 1 + 5 + z.REID.Msgsize() + 5 + msgp.IntSize + 9 + msgp.StringPrefixSize + len(z.RESPONSE) + 5 + z.META.Msgsize() + 5 + z.PAYL.Msgsize() + 9 + msgp.ArrayHeaderSize
 	for za0001 := range z.WARNINGS {
 		s += msgp.StringPrefixSize + len(z.WARNINGS[za0001])
 	}
@@ -1,7 +1,7 @@
-package types
-
 // Code generated by github.com/tinylib/msgp DO NOT EDIT.
 
+package types
+
 import (
 	"bytes"
 	"testing"
@@ -4,10 +4,26 @@ import "github.com/tinylib/msgp/msgp"
 
 //go:generate msgp
 
-// The snapshot type defines the contents of the snapshot.beacon file
+// The snapshot type defines the contents of the snapshot.beacon file of older versions (see SnapshotFile)
 // It maps paths (concatenated with "/") to resource contents (raw msgpack)
 type Snapshot map[string]msgp.Raw
 
 func NewSnapshot() Snapshot {
 	return make(map[string]msgp.Raw)
 }
+
+// SnapshotFormat is the format of the snapshot files written by this version (see SnapshotFile)
+const SnapshotFormat = 1
+
+// SnapshotFile defines the contents of snapshot files with a format (snapshot files of older versions only contain a Snapshot).
+// It maps paths (concatenated with "/") to resource contents with their versions.
+type SnapshotFile struct {
+	Format    int                         `msg:"FORMAT"`
+	Resources map[string]SnapshotResource `msg:"RESOURCES"`
+}
+
+// SnapshotResource is the content (raw msgpack) and version of a resource in a SnapshotFile
+type SnapshotResource struct {
+	Value   msgp.Raw `msg:"VALUE"`
+	Version uint64   `msg:"VERSION"`
+}
@@ -1,7 +1,7 @@
-package types
-
 // Code generated by github.com/tinylib/msgp DO NOT EDIT.
 
+package types
+
 import (
 	"github.com/tinylib/msgp/msgp"
 )
@@ -17,21 +17,19 @@ func (z *Snapshot) DecodeMsg(dc *msgp.Reader) (err error) {
 	if (*z) == nil {
 		(*z) = make(Snapshot, zb0003)
 	} else if len((*z)) > 0 {
-		for key := range *z {
-			delete((*z), key)
-		}
+		clear((*z))
 	}
 	var field []byte
 	_ = field
 	for zb0003 > 0 {
 		zb0003--
 		var zb0001 string
-		var zb0002 msgp.Raw
 		zb0001, err = dc.ReadString()
 		if err != nil {
 			err = msgp.WrapError(err)
 			return
 		}
+		var zb0002 msgp.Raw
 		err = zb0002.DecodeMsg(dc)
 		if err != nil {
 			err = msgp.WrapError(err, zb0001)
@@ -90,16 +88,14 @@ func (z *Snapshot) UnmarshalMsg(bts []byte) (o []byte, err error) {
 	if (*z) == nil {
 		(*z) = make(Snapshot, zb0003)
 	} else if len((*z)) > 0 {
-		for key := range *z {
-			delete((*z), key)
-		}
+		clear((*z))
 	}
 	var field []byte
 	_ = field
 	for zb0003 > 0 {
-		var zb0001 string
 		var zb0002 msgp.Raw
 		zb0003--
+		var zb0001 string
 		zb0001, bts, err = msgp.ReadStringBytes(bts)
 		if err != nil {
 			err = msgp.WrapError(err)
@@ -127,3 +123,413 @@ func (z Snapshot) Msgsize() (s int) {
 	}
 	return
 }
+
+// DecodeMsg implements msgp.Decodable
+func (z *SnapshotFile) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "FORMAT":
+			z.Format, err = dc.ReadInt()
+			if err != nil {
+				err = msgp.WrapError(err, "Format")
+				return
+			}
+		case "RESOURCES":
+			var zb0002 uint32
+			zb0002, err = dc.ReadMapHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Resources")
+				return
+			}
+			if z.Resources == nil {
+				z.Resources = make(map[string]SnapshotResource, zb0002)
+			} else if len(z.Resources) > 0 {
+				clear(z.Resources)
+			}
+			for zb0002 > 0 {
+				zb0002--
+				var za0001 string
+				za0001, err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "Resources")
+					return
+				}
+				var za0002 SnapshotResource
+				var zb0003 uint32
+				zb0003, err = dc.ReadMapHeader()
+				if err != nil {
+					err = msgp.WrapError(err, "Resources", za0001)
+					return
+				}
+				for zb0003 > 0 {
+					zb0003--
+					field, err = dc.ReadMapKeyPtr()
+					if err != nil {
+						err = msgp.WrapError(err, "Resources", za0001)
+						return
+					}
+					switch msgp.UnsafeString(field) {
+					case "VALUE":
+						err = za0002.Value.DecodeMsg(dc)
+						if err != nil {
+							err = msgp.WrapError(err, "Resources", za0001, "Value")
+							return
+						}
+					case "VERSION":
+						za0002.Version, err = dc.ReadUint64()
+						if err != nil {
+							err = msgp.WrapError(err, "Resources", za0001, "Version")
+							return
+						}
+					default:
+						err = dc.Skip()
+						if err != nil {
+							err = msgp.WrapError(err, "Resources", za0001)
+							return
+						}
+					}
+				}
+				z.Resources[za0001] = za0002
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *SnapshotFile) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "FORMAT"
+	err = en.Append(0x82, 0xa6, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt(z.Format)
+	if err != nil {
+		err = msgp.WrapError(err, "Format")
+		return
+	}
+	// write "RESOURCES"
+	err = en.Append(0xa9, 0x52, 0x45, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x53)
+	if err != nil {
+		return
+	}
+	err = en.WriteMapHeader(uint32(len(z.Resources)))
+	if err != nil {
+		err = msgp.WrapError(err, "Resources")
+		return
+	}
+	for za0001, za0002 := range z.Resources {
+		err = en.WriteString(za0001)
+		if err != nil {
+			err = msgp.WrapError(err, "Resources")
+			return
+		}
+		// map header, size 2
+		// write "VALUE"
+		err = en.Append(0x82, 0xa5, 0x56, 0x41, 0x4c, 0x55, 0x45)
+		if err != nil {
+			return
+		}
+		err = za0002.Value.EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Resources", za0001, "Value")
+			return
+		}
+		// write "VERSION"
+		err = en.Append(0xa7, 0x56, 0x45, 0x52, 0x53, 0x49, 0x4f, 0x4e)
+		if err != nil {
+			return
+		}
+		err = en.WriteUint64(za0002.Version)
+		if err != nil {
+			err = msgp.WrapError(err, "Resources", za0001, "Version")
+			return
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *SnapshotFile) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "FORMAT"
+	o = append(o, 0x82, 0xa6, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54)
+	o = msgp.AppendInt(o, z.Format)
+	// string "RESOURCES"
+	o = append(o, 0xa9, 0x52, 0x45, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x53)
+	o = msgp.AppendMapHeader(o, uint32(len(z.Resources)))
+	for za0001, za0002 := range z.Resources {
+		o = msgp.AppendString(o, za0001)
+		// map header, size 2
+		// string "VALUE"
+		o = append(o, 0x82, 0xa5, 0x56, 0x41, 0x4c, 0x55, 0x45)
+		o, err = za0002.Value.MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Resources", za0001, "Value")
+			return
+		}
+		// string "VERSION"
+		o = append(o, 0xa7, 0x56, 0x45, 0x52, 0x53, 0x49, 0x4f, 0x4e)
+		o = msgp.AppendUint64(o, za0002.Version)
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *SnapshotFile) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "FORMAT":
+			z.Format, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Format")
+				return
+			}
+		case "RESOURCES":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadMapHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Resources")
+				return
+			}
+			if z.Resources == nil {
+				z.Resources = make(map[string]SnapshotResource, zb0002)
+			} else if len(z.Resources) > 0 {
+				clear(z.Resources)
+			}
+			for zb0002 > 0 {
+				var za0002 SnapshotResource
+				zb0002--
+				var za0001 string
+				za0001, bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Resources")
+					return
+				}
+				var zb0003 uint32
+				zb0003, bts, err = msgp.ReadMapHeaderBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Resources", za0001)
+					return
+				}
+				for zb0003 > 0 {
+					zb0003--
+					field, bts, err = msgp.ReadMapKeyZC(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "Resources", za0001)
+						return
+					}
+					switch msgp.UnsafeString(field) {
+					case "VALUE":
+						bts, err = za0002.Value.UnmarshalMsg(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Resources", za0001, "Value")
+							return
+						}
+					case "VERSION":
+						za0002.Version, bts, err = msgp.ReadUint64Bytes(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Resources", za0001, "Version")
+							return
+						}
+					default:
+						bts, err = msgp.Skip(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Resources", za0001)
+							return
+						}
+					}
+				}
+				z.Resources[za0001] = za0002
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *SnapshotFile) Msgsize() (s int) {
+	s = 1 + 7 + msgp.IntSize + 10 + msgp.MapHeaderSize
+	if z.Resources != nil {
+		for za0001, za0002 := range z.Resources {
+			_ = za0002
+			s += msgp.StringPrefixSize + len(za0001) + 1 + 6 + za0002.Value.Msgsize() + 8 + msgp.Uint64Size
+		}
+	}
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *SnapshotResource) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "VALUE":
+			err = z.Value.DecodeMsg(dc)
+			if err != nil {
+				err = msgp.WrapError(err, "Value")
+				return
+			}
+		case "VERSION":
+			z.Version, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "Version")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *SnapshotResource) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 2
+	// write "VALUE"
+	err = en.Append(0x82, 0xa5, 0x56, 0x41, 0x4c, 0x55, 0x45)
+	if err != nil {
+		return
+	}
+	err = z.Value.EncodeMsg(en)
+	if err != nil {
+		err = msgp.WrapError(err, "Value")
+		return
+	}
+	// write "VERSION"
+	err = en.Append(0xa7, 0x56, 0x45, 0x52, 0x53, 0x49, 0x4f, 0x4e)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Version)
+	if err != nil {
+		err = msgp.WrapError(err, "Version")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *SnapshotResource) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 2
+	// string "VALUE"
+	o = append(o, 0x82, 0xa5, 0x56, 0x41, 0x4c, 0x55, 0x45)
+	o, err = z.Value.MarshalMsg(o)
+	if err != nil {
+		err = msgp.WrapError(err, "Value")
+		return
+	}
+	// string "VERSION"
+	o = append(o, 0xa7, 0x56, 0x45, 0x52, 0x53, 0x49, 0x4f, 0x4e)
+	o = msgp.AppendUint64(o, z.Version)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *SnapshotResource) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "VALUE":
+			bts, err = z.Value.UnmarshalMsg(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Value")
+				return
+			}
+		case "VERSION":
+			z.Version, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Version")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *SnapshotResource) Msgsize() (s int) {
+	s = 1 + 6 + z.Value.Msgsize() + 8 + msgp.Uint64Size
+	return
+}